@@ -0,0 +1,228 @@
+package goerr
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Code classifies an error, allowing it to be mapped to
+// transport statuses, retry policies and the like.
+//
+// The values mirror the canonical gRPC status codes,
+// see https://grpc.github.io/grpc/core/md_doc_statuscodes.html
+type Code int
+
+const (
+	// OK is the zero value, it means no code has been set.
+	OK Code = iota
+	// Canceled indicates the operation was canceled, typically by the caller.
+	Canceled
+	// Unknown indicates an unknown error, this is the code of any error
+	// that has not otherwise been classified. see CodeOf
+	Unknown
+	// InvalidArgument indicates the caller specified an invalid argument.
+	InvalidArgument
+	// DeadlineExceeded means the operation expired before completion.
+	DeadlineExceeded
+	// NotFound means some requested entity was not found.
+	NotFound
+	// AlreadyExists means an attempt to create an entity failed
+	// because one already exists.
+	AlreadyExists
+	// PermissionDenied indicates the caller does not have permission
+	// to execute the specified operation.
+	PermissionDenied
+	// ResourceExhausted indicates some resource has been exhausted.
+	ResourceExhausted
+	// FailedPrecondition indicates the operation was rejected because the
+	// system is not in a state required for the operation's execution.
+	FailedPrecondition
+	// Aborted indicates the operation was aborted, typically due to
+	// a concurrency issue like a transaction abort.
+	Aborted
+	// OutOfRange means the operation was attempted past the valid range.
+	OutOfRange
+	// Unimplemented indicates the operation is not implemented
+	// or not supported/enabled.
+	Unimplemented
+	// Internal errors, some invariant expected by the system has been broken.
+	Internal
+	// Unavailable indicates the service is currently unavailable,
+	// this is most likely a transient condition.
+	Unavailable
+	// DataLoss indicates unrecoverable data loss or corruption.
+	DataLoss
+	// Unauthenticated indicates the request does not have
+	// valid authentication credentials for the operation.
+	Unauthenticated
+)
+
+var codeNames = [...]string{
+	OK:                 "OK",
+	Canceled:           "Canceled",
+	Unknown:            "Unknown",
+	InvalidArgument:    "InvalidArgument",
+	DeadlineExceeded:   "DeadlineExceeded",
+	NotFound:           "NotFound",
+	AlreadyExists:      "AlreadyExists",
+	PermissionDenied:   "PermissionDenied",
+	ResourceExhausted:  "ResourceExhausted",
+	FailedPrecondition: "FailedPrecondition",
+	Aborted:            "Aborted",
+	OutOfRange:         "OutOfRange",
+	Unimplemented:      "Unimplemented",
+	Internal:           "Internal",
+	Unavailable:        "Unavailable",
+	DataLoss:           "DataLoss",
+	Unauthenticated:    "Unauthenticated",
+}
+
+var codeHTTPStatuses = [...]int{
+	OK:                 http.StatusOK,
+	Canceled:           499,
+	Unknown:            http.StatusInternalServerError,
+	InvalidArgument:    http.StatusBadRequest,
+	DeadlineExceeded:   http.StatusGatewayTimeout,
+	NotFound:           http.StatusNotFound,
+	AlreadyExists:      http.StatusConflict,
+	PermissionDenied:   http.StatusForbidden,
+	ResourceExhausted:  http.StatusTooManyRequests,
+	FailedPrecondition: http.StatusBadRequest,
+	Aborted:            http.StatusConflict,
+	OutOfRange:         http.StatusBadRequest,
+	Unimplemented:      http.StatusNotImplemented,
+	Internal:           http.StatusInternalServerError,
+	Unavailable:        http.StatusServiceUnavailable,
+	DataLoss:           http.StatusInternalServerError,
+	Unauthenticated:    http.StatusUnauthorized,
+}
+
+// ParseCode returns the Code with the given name, as returned by
+// Code.String(), Unknown is returned for unrecognised names.
+func ParseCode(name string) Code {
+	for c, n := range codeNames {
+		if n == name {
+			return Code(c)
+		}
+	}
+	return Unknown
+}
+
+// String implements the Stringer interface.
+func (c Code) String() string {
+	if c < 0 || int(c) >= len(codeNames) {
+		return fmt.Sprintf("Code(%d)", int(c))
+	}
+	return codeNames[c]
+}
+
+// HTTPStatus returns the HTTP status code that best matches the Code.
+func (c Code) HTTPStatus() int {
+	if c < 0 || int(c) >= len(codeHTTPStatuses) {
+		return http.StatusInternalServerError
+	}
+	return codeHTTPStatuses[c]
+}
+
+// CodeFromHTTPStatus returns the Code that best matches a HTTP status code.
+// OK is returned for any non error status & Unknown for unrecognised errors.
+func CodeFromHTTPStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return InvalidArgument
+	case http.StatusUnauthorized:
+		return Unauthenticated
+	case http.StatusForbidden:
+		return PermissionDenied
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusConflict:
+		return Aborted
+	case http.StatusTooManyRequests:
+		return ResourceExhausted
+	case 499:
+		return Canceled
+	case http.StatusNotImplemented:
+		return Unimplemented
+	case http.StatusServiceUnavailable:
+		return Unavailable
+	case http.StatusGatewayTimeout:
+		return DeadlineExceeded
+	}
+	switch {
+	case status < 400:
+		return OK
+	case status >= 500:
+		return Internal
+	}
+	return Unknown
+}
+
+// MarshalText implements the encoding.TextMarshaler interface,
+// such that codes are represented by name in JSON.
+func (c Code) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// GoString implements the GoStringer interface,
+// such that %#v formats a Code as valid Go syntax.
+func (c Code) GoString() string {
+	if c < 0 || int(c) >= len(codeNames) {
+		return fmt.Sprintf("goerr.Code(%d)", int(c))
+	}
+	return "goerr." + codeNames[c]
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+//
+// Unlike ParseCode an error is returned for unrecognised names,
+// other than those of the form Code(n) as returned by Code.String().
+func (c *Code) UnmarshalText(text []byte) error {
+	for i, name := range codeNames {
+		if name == string(text) {
+			*c = Code(i)
+			return nil
+		}
+	}
+	var n int
+	if _, err := fmt.Sscanf(string(text), "Code(%d)", &n); err == nil && fmt.Sprintf("Code(%d)", n) == string(text) {
+		*c = Code(n)
+		return nil
+	}
+	return fmt.Errorf("unknown code %q", text)
+}
+
+// WithCode sets the Code that classifies the error.
+func WithCode(c Code) Option {
+	return func(e *Error) {
+		e.code = c
+	}
+}
+
+// CodeOf returns the Code of the first error in err's chain that has one.
+//
+// Any error that implements a method Code() Code is considered, such as
+// *Error & *RemoteError. If err is nil OK is returned and if no Code can
+// be found then Unknown is returned.
+func CodeOf(err error) Code {
+	if err == nil {
+		return OK
+	}
+	if code := codeOf(err); code != OK {
+		return code
+	}
+	return Unknown
+}
+
+// codeOf returns the first Code found in err's chain or OK if none is found.
+func codeOf(err error) Code {
+	code := OK
+	walk(err, func(e error) bool {
+		if coder, ok := e.(interface{ Code() Code }); ok && coder.Code() != OK {
+			code = coder.Code()
+			return false
+		}
+		return true
+	})
+	return code
+}
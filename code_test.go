@@ -0,0 +1,79 @@
+package goerr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var errCodeNotFound = goerr.NewWith("not found", goerr.WithCode(goerr.NotFound))
+
+func TestCodeOf(t *testing.T) {
+	assert.Equal(t, goerr.OK, goerr.CodeOf(nil))
+	assert.Equal(t, goerr.Unknown, goerr.CodeOf(errors.New("abc")))
+	assert.Equal(t, goerr.NotFound, goerr.CodeOf(errCodeNotFound))
+	assert.Equal(t, goerr.NotFound, goerr.CodeOf(fmt.Errorf("%w", goerr.Wrap(errCodeNotFound))))
+	assert.Equal(t, goerr.Unavailable, goerr.CodeOf(goerr.WrapWith(errCodeNotFound, goerr.WithCode(goerr.Unavailable))))
+	assert.Equal(t, goerr.NotFound, goerr.CodeOf(goerr.Join(errors.New("abc"), errCodeNotFound)))
+}
+
+func TestCodeString(t *testing.T) {
+	assert.Equal(t, "InvalidArgument", goerr.InvalidArgument.String())
+	assert.Equal(t, "Code(99)", goerr.Code(99).String())
+	assert.Equal(t, goerr.Internal, goerr.ParseCode("Internal"))
+	assert.Equal(t, goerr.Unknown, goerr.ParseCode("Foo"))
+	assert.Equal(t, "goerr.NotFound", fmt.Sprintf("%#v", goerr.NotFound))
+	assert.Equal(t, "goerr.Code(99)", fmt.Sprintf("%#v", goerr.Code(99)))
+	assert.Contains(t, fmt.Sprintf("%#v", errCodeNotFound), ", code:goerr.NotFound,")
+}
+
+func TestCodeText(t *testing.T) {
+	for _, c := range []goerr.Code{goerr.OK, goerr.NotFound, goerr.Unauthenticated, goerr.Code(99)} {
+		text, err := c.MarshalText()
+		assert.NoError(t, err)
+		var parsed goerr.Code
+		assert.NoError(t, parsed.UnmarshalText(text))
+		assert.Equal(t, c, parsed)
+	}
+	assert.Error(t, new(goerr.Code).UnmarshalText([]byte("Foo")))
+	assert.Error(t, new(goerr.Code).UnmarshalText([]byte("Code(1)x")))
+	_, err := goerr.ParseTrace([]byte(`{"error-code":"Foo"}`))
+	assert.Error(t, err)
+}
+
+func TestCodeHTTPStatus(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, goerr.NotFound.HTTPStatus())
+	assert.Equal(t, http.StatusServiceUnavailable, goerr.Unavailable.HTTPStatus())
+	assert.Equal(t, http.StatusInternalServerError, goerr.Code(99).HTTPStatus())
+}
+
+func TestStackTraceCode(t *testing.T) {
+	st := goerr.NewStackTrace(goerr.Wrap(errCodeNotFound, "loading user"))
+	assert.Equal(t, goerr.NotFound, st.Code)
+	assert.True(t, strings.HasPrefix(st.String(), "loading user: not found [NotFound]\n\n"))
+
+	j, err := json.Marshal(st)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(j), `"error-code":"NotFound"`)
+		parsed, err := goerr.ParseTrace(j)
+		if assert.NoError(t, err) {
+			assert.Equal(t, goerr.NotFound, parsed.Code)
+			assert.Equal(t, goerr.NotFound, goerr.CodeOf(parsed.Error))
+		}
+	}
+
+	assert.Equal(t, goerr.OK, goerr.NewStackTrace(errors.New("abc")).Code)
+}
+
+func TestCodeFromHTTPStatus(t *testing.T) {
+	assert.Equal(t, goerr.OK, goerr.CodeFromHTTPStatus(http.StatusOK))
+	assert.Equal(t, goerr.NotFound, goerr.CodeFromHTTPStatus(http.StatusNotFound))
+	assert.Equal(t, goerr.Unknown, goerr.CodeFromHTTPStatus(http.StatusTeapot))
+	assert.Equal(t, goerr.Internal, goerr.CodeFromHTTPStatus(http.StatusBadGateway))
+}
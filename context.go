@@ -0,0 +1,146 @@
+package goerr
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ContextExtractor returns the values of a context.Context that should be
+// captured as context values of an error, see RegisterContextExtractor.
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+type namedExtractor struct {
+	name string
+	fn   ContextExtractor
+}
+
+var defaultContextExtractors = []namedExtractor{
+	{"deadline", DeadlineExtractor},
+	{"cancellation", CancellationExtractor},
+}
+
+var extractors = &extractorRegistry{
+	extractors: append([]namedExtractor{}, defaultContextExtractors...),
+}
+
+type extractorRegistry struct {
+	mu         sync.RWMutex
+	extractors []namedExtractor
+}
+
+// RegisterContextExtractor registers fn under name, replacing any extractor
+// already registered with the same name. Extractors are run by WrapCtx &
+// WithContext in the order they were registered.
+//
+// By default the "deadline" & "cancellation" extractors are registered,
+// see DeadlineExtractor & CancellationExtractor.
+func RegisterContextExtractor(name string, fn ContextExtractor) {
+	extractors.mu.Lock()
+	defer extractors.mu.Unlock()
+	for i, e := range extractors.extractors {
+		if e.name == name {
+			extractors.extractors[i].fn = fn
+			return
+		}
+	}
+	extractors.extractors = append(extractors.extractors, namedExtractor{name, fn})
+}
+
+// UnregisterContextExtractor removes the extractor registered under name.
+func UnregisterContextExtractor(name string) {
+	extractors.mu.Lock()
+	defer extractors.mu.Unlock()
+	for i, e := range extractors.extractors {
+		if e.name == name {
+			extractors.extractors = append(extractors.extractors[:i:i], extractors.extractors[i+1:]...)
+			return
+		}
+	}
+}
+
+// ResetContextExtractors restores the default extractors, removing any others.
+func ResetContextExtractors() {
+	extractors.mu.Lock()
+	defer extractors.mu.Unlock()
+	extractors.extractors = append([]namedExtractor{}, defaultContextExtractors...)
+}
+
+// ContextValue returns a ContextExtractor that captures ctx.Value(key) as
+// field, for example a request id stored in the context by middleware:
+//
+//	goerr.RegisterContextExtractor("request_id", goerr.ContextValue(requestIDKey{}, "request_id"))
+func ContextValue(key interface{}, field string) ContextExtractor {
+	return func(ctx context.Context) map[string]interface{} {
+		if v := ctx.Value(key); v != nil {
+			return map[string]interface{}{field: v}
+		}
+		return nil
+	}
+}
+
+// DeadlineExtractor captures the time remaining until the deadline of
+// the context, if it has one, as "ctx_deadline_remaining".
+func DeadlineExtractor(ctx context.Context) map[string]interface{} {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	return map[string]interface{}{
+		"ctx_deadline_remaining": time.Until(deadline).Round(time.Millisecond).String(),
+	}
+}
+
+// CancellationExtractor captures the error of a context that is done as
+// "ctx_err" along with its cause, if different, as "ctx_cause".
+func CancellationExtractor(ctx context.Context) map[string]interface{} {
+	err := ctx.Err()
+	if err == nil {
+		return nil
+	}
+	out := map[string]interface{}{"ctx_err": err.Error()}
+	if cause := context.Cause(ctx); cause != nil && cause != err {
+		out["ctx_cause"] = cause.Error()
+	}
+	return out
+}
+
+// WithContext captures the values of ctx returned by the registered
+// extractors as context values of the error, see RegisterContextExtractor.
+//
+// The values are made safe to encode, as per the values of a StackTrace, and
+// should an extractor panic a "<panic: ...>" value is captured under its name.
+func WithContext(ctx context.Context) Option {
+	return func(e *Error) {
+		if ctx == nil {
+			return
+		}
+		extractors.mu.RLock()
+		registered := append([]namedExtractor{}, extractors.extractors...)
+		extractors.mu.RUnlock()
+
+		for _, x := range registered {
+			values, _ := safeCtx(runExtractor(x, ctx))
+			for _, k := range sortedKeys(values) {
+				e.fields = append(e.fields, field{k, values[k]})
+			}
+		}
+	}
+}
+
+// runExtractor calls the extractor, recovering from any panic.
+func runExtractor(x namedExtractor, ctx context.Context) (values map[string]interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			values = map[string]interface{}{x.name: fmt.Sprintf("<panic: %v>", r)}
+		}
+	}()
+	return x.fn(ctx)
+}
+
+// WrapCtx is the same as Wrap but also captures request scoped values
+// from ctx, such as a request id or the deadline, see WithContext.
+func WrapCtx(ctx context.Context, value interface{}, messages ...string) *Error {
+	return TraceWith(1, value, Msg(messages...), WithContext(ctx))
+}
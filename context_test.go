@@ -0,0 +1,92 @@
+package goerr_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type requestIDKey struct{}
+
+func TestWrapCtx(t *testing.T) {
+	defer goerr.ResetContextExtractors()
+	goerr.RegisterContextExtractor("request_id", goerr.ContextValue(requestIDKey{}, "request_id"))
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "abc-123")
+	ctx, cancel := context.WithTimeout(ctx, time.Hour)
+	defer cancel()
+
+	e := goerr.WrapCtx(ctx, errors.New("boom"), "loading user")
+	assert.EqualError(t, e, "loading user: boom")
+	assert.Equal(t, "TestWrapCtx", e.Frame().Name)
+
+	st := goerr.NewStackTrace(e)
+	assert.Equal(t, "abc-123", st.ErrorCtx["request_id"])
+	remaining, err := time.ParseDuration(st.ErrorCtx["ctx_deadline_remaining"].(string))
+	assert.NoError(t, err)
+	assert.True(t, remaining > 59*time.Minute)
+	assert.NotContains(t, st.ErrorCtx, "ctx_err")
+
+	j, err := json.Marshal(st)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(j), `"request_id":"abc-123"`)
+	}
+}
+
+func TestWrapCtxCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("client went away"))
+
+	st := goerr.NewStackTrace(goerr.WrapCtx(ctx, errors.New("boom")))
+	assert.Equal(t, "context canceled", st.ErrorCtx["ctx_err"])
+	assert.Equal(t, "client went away", st.ErrorCtx["ctx_cause"])
+	assert.NotContains(t, st.ErrorCtx, "ctx_deadline_remaining")
+}
+
+func TestContextExtractorRegistry(t *testing.T) {
+	defer goerr.ResetContextExtractors()
+	goerr.UnregisterContextExtractor("cancellation")
+	goerr.RegisterContextExtractor("tenant", func(ctx context.Context) map[string]interface{} {
+		return map[string]interface{}{"tenant": "a"}
+	})
+	goerr.RegisterContextExtractor("tenant", func(ctx context.Context) map[string]interface{} {
+		return map[string]interface{}{"tenant": "b"}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	e := goerr.WrapWith(errors.New("boom"), goerr.WithContext(ctx))
+	assert.Equal(t, map[string]interface{}{"tenant": "b"}, e.Fields())
+
+	goerr.ResetContextExtractors()
+	assert.Equal(t, "context canceled", goerr.WrapWith(errors.New("boom"), goerr.WithContext(ctx)).Fields()["ctx_err"])
+	assert.Nil(t, goerr.WrapWith(errors.New("boom"), goerr.WithContext(nil)).Fields())
+}
+
+func TestContextExtractorSafe(t *testing.T) {
+	defer goerr.ResetContextExtractors()
+	goerr.RegisterContextExtractor("broken", func(ctx context.Context) map[string]interface{} {
+		panic("boom")
+	})
+	goerr.RegisterContextExtractor("values", func(ctx context.Context) map[string]interface{} {
+		// Extractors may use the registry as they are not run under its lock
+		goerr.UnregisterContextExtractor("cancellation")
+		return map[string]interface{}{
+			"ch":       make(chan int),
+			"big":      strings.Repeat("a", goerr.MaxCtxValueLen*2),
+			"password": "hunter2",
+		}
+	})
+
+	fields := goerr.WrapWith(errors.New("boom"), goerr.WithContext(context.Background())).Fields()
+	assert.Equal(t, "<panic: boom>", fields["broken"])
+	assert.IsType(t, "", fields["ch"])
+	assert.True(t, len(fields["big"].(string)) < goerr.MaxCtxValueLen*2)
+	assert.Equal(t, goerr.Redacted, fields["password"])
+}
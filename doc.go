@@ -85,6 +85,44 @@ And see output similar to:
 	main.crash1:C:/Users/brad.jones/Projects/Personal/goerr/examples/simple/main.go:15
 		return goerr.Wrap(err)
 
+Structured context can be attached at any wrap point with WrapWith & With,
+it is merged into the ErrorCtx of the trace and shown against the frame that set it:
+
+	return goerr.WrapWith(err, goerr.Msg("failed to load user"), goerr.With("user_id", id))
+
+WrapCtx also captures request scoped values from a context.Context, such as
+the time remaining until its deadline or the cause of its cancellation. Further
+values, like a request id, can be captured by registering a ContextExtractor:
+
+	goerr.RegisterContextExtractor("request_id", goerr.ContextValue(requestIDKey{}, "request_id"))
+
+	return goerr.WrapCtx(ctx, err, "failed to load user")
+
+Sentinel errors have a stable identity for Is and are traced to where they
+are raised with With, rather than where they were declared:
+
+	var errNotFound = goerr.Sentinel("user %s not found", goerr.WithCode(goerr.NotFound))
+
+	return errNotFound.With(id)
+
+User facing messages can be set with a Template instead of Msg, the raw
+template and params are kept in the trace and Localize renders the message
+chain for a locale using a Localizer, such as a Catalogue loaded from
+JSON or TOML files with LoadCatalogue:
+
+	err = goerr.WrapWith(err, goerr.Template("user {id} not found", goerr.Params{"id": id}))
+
+	msg := goerr.Localize(err, "fr", catalogue)
+
+Errors can be marked as retryable with WithRetry, WithBackoff & WithMaxAttempts.
+IsRetryable & RetryAfter walk the chain, also honouring the Temporary() &
+Timeout() methods of errors from other packages, and Retry uses them to call
+a function until it succeeds, joining the error of every failed attempt:
+
+	err := goerr.Retry(ctx, goerr.DefaultRetryPolicy, func(ctx context.Context) error {
+		return goerr.WrapWith(fetch(ctx), goerr.WithRetry(true))
+	})
+
 Intent
 
 To borrow from "palantir/stacktrace" the intent is not that we capture the exact
@@ -95,6 +133,18 @@ The intent here is to attach relevant contextual information (messages, variable
 at strategic places along the call stack, keeping stack traces compact and
 maximally useful.
 
+That said sometimes you do want the whole picture, full stack capture can be
+enabled for the entire program with SetFullStack(true) or for a single call
+with WrapWith(err, FullStack()). The deepest traced error in the chain will then
+record the complete call stack and the trace will render every frame with the
+explicit wrap points highlighted with a "*".
+
+Every trace also has a Fingerprint, a stable identity calculated from the
+type of the cause and the frames of the trace that ignores messages and
+context values, such that the same failure occurring many times can be
+grouped. It is included in the JSON output of a trace and can be shown by
+the text renderer with TextRenderer{Fingerprint: true}.
+
 Check and Handle
 
 This is totally an experiment, YMMV :)
@@ -131,6 +181,23 @@ So `Check()` replaces the repetitive `if err != nil { ... }` phrase and
 `Handle` takes care of the `recover()` logic for you. `Check()` automatically
 calls `Trace()` on your error.
 
+`Must` & `Must2` do the same for calls that also return values, so the
+two-step `r, err := os.Open(src); Check(err)` becomes an expression:
+
+	r := Must(os.Open(src))
+
+`Try` goes the other way, running a function written in this style and
+returning any checked error as an ordinary error.
+
+`Handle` will recover any panic, if you would rather real bugs such as a nil
+pointer dereference were not turned into ordinary errors use `HandleCheck`
+which only recovers panics raised by `Check` or `HandleRuntime` which also
+recovers runtime errors, capturing the full stack at the time of the panic.
+
+Errors from any other panic are traced to the frame that actually panicked
+and are marked as "(recovered from panic)" in their trace. Call `Recovered`
+from your own deferred function to do the same, for example in middleware.
+
 Yeah I get it this looks like exceptions and if you choose to use it like that
 then thats your prerogative, I'm not going to stop you... but you probably
 shouldn't!
@@ -141,6 +208,19 @@ https://go101.org/article/panic-and-recover-more.html
 Although there is https://github.com/brad-jones/goasync which makes use of this
 package to handle errors across goroutines (including panics), reasonable well.
 
+Or for simple cases use Go & Group which will recover any panics (including
+those from Check) in the goroutines they spawn and return them from Wait:
+
+	g := Go(func() error {
+		Check(build("foo"))
+		return nil
+	})
+	g.Go(func() error {
+		Check(build("bar"))
+		return nil
+	})
+	err := g.Wait()
+
 I think where this can be really useful is when you say have a function like this:
 
 	func DoSomeWork() (err error) {
@@ -2,6 +2,10 @@ package goerr
 
 import (
 	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"time"
 )
 
 // Error is an error object that stores stack frame information,
@@ -10,6 +14,24 @@ type Error struct {
 	message  string
 	innerErr error
 	caller   uintptr
+
+	stack     []uintptr
+	fullStack bool
+	fields    []field
+	code      Code
+	checked   bool
+	panic     PanicKind
+	panicPC   uintptr
+	template  *MessageTemplate
+
+	retryable   retryable
+	backoff     time.Duration
+	maxAttempts int
+}
+
+type field struct {
+	key   string
+	value interface{}
 }
 
 // New is the constructor for the `Error` object.
@@ -25,6 +47,19 @@ func New(value interface{}) *Error {
 	return &Error{innerErr: err}
 }
 
+// NewWith is the same as New but also accepts a variadic number of Options.
+//
+// For example:
+//
+//	var errNotFound = goerr.NewWith("not found", goerr.WithCode(goerr.NotFound))
+func NewWith(value interface{}, opts ...Option) *Error {
+	err := New(value)
+	for _, opt := range opts {
+		opt(err)
+	}
+	return err
+}
+
 // Error implements the stdlib error interface.
 func (g *Error) Error() string {
 	if g.message == "" {
@@ -33,6 +68,73 @@ func (g *Error) Error() string {
 	return fmt.Sprintf("%s: %s", g.message, g.innerErr.Error())
 }
 
+// Format implements the fmt.Formatter interface.
+//
+// The %v, %s & %q verbs output the error message,
+// %+v outputs the complete stack trace as per NewStackTrace
+// and %#v outputs a Go-syntax representation of the error chain.
+func (g *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, NewStackTrace(g).String())
+			return
+		}
+		if s.Flag('#') {
+			io.WriteString(s, g.GoString())
+			return
+		}
+		io.WriteString(s, g.Error())
+	case 's':
+		io.WriteString(s, g.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", g.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(*goerr.Error=%s)", verb, g.Error())
+	}
+}
+
+// GoString implements the fmt.GoStringer interface.
+// Sensitive data is redacted, see RedactKeys & RedactMessages.
+func (g *Error) GoString() string {
+	fields := redactCtx(g.Fields())
+	var frame *StackFrame
+	if g.caller != 0 {
+		frame = g.Frame()
+		frame.Context = fields
+	}
+	str := fmt.Sprintf("&goerr.Error{message:%q, frame:%#v, fields:%#v",
+		redactMessage(g.message), frame, fields,
+	)
+	if g.code != OK {
+		str = str + fmt.Sprintf(", code:%#v", g.code)
+	}
+	if g.stack != nil {
+		str = str + fmt.Sprintf(", stack:%#v", g.Stack())
+	}
+	return str + fmt.Sprintf(", innerErr:%s}", goString(g.innerErr))
+}
+
+// goString returns a Go-syntax representation of err with any sensitive
+// data redacted. Errors of this package redact themselves, other errors
+// with fields tagged `goerr:"redact"` are output as their redacted JSON
+// representation & any with a sensitive message as just the message.
+func goString(err error) string {
+	switch err.(type) {
+	case nil, *Error, *MultiError:
+		return fmt.Sprintf("%#v", err)
+	}
+	if hasRedactTag(reflect.TypeOf(err)) {
+		if m := marshalError(err); m != nil {
+			return fmt.Sprintf("%T(%#v)", err, m)
+		}
+	}
+	if msg := err.Error(); redactMessage(msg) != msg {
+		return fmt.Sprintf("%T(%q)", err, redactMessage(msg))
+	}
+	return fmt.Sprintf("%#v", err)
+}
+
 // Unwrap implements the stdlib error interface.
 func (g *Error) Unwrap() error {
 	return g.innerErr
@@ -40,5 +142,74 @@ func (g *Error) Unwrap() error {
 
 // Frame returns the stack frame object attached to this error.
 func (g *Error) Frame() *StackFrame {
-	return NewStackFrame(g.caller)
+	frame := NewStackFrame(g.caller)
+	frame.Context = g.Fields()
+	return frame
+}
+
+// Panic returns how this error was recovered from a panic, see Recovered.
+func (g *Error) Panic() PanicKind {
+	return g.panic
+}
+
+// PanicFrame returns the frame that panicked, for errors raised by Check
+// this is the frame that called Check. nil is returned if this error was
+// not recovered from a panic.
+func (g *Error) PanicFrame() *StackFrame {
+	if g.panicPC == 0 {
+		return nil
+	}
+	return NewStackFrame(g.panicPC)
+}
+
+// Code returns the Code set on this error with WithCode, if any.
+// see CodeOf to find the Code of an entire error chain.
+func (g *Error) Code() Code {
+	return g.code
+}
+
+// Fields returns the structured context attached to this error with With.
+// Later values take precedence should the same key be set more than once.
+func (g *Error) Fields() map[string]interface{} {
+	if len(g.fields) == 0 {
+		return nil
+	}
+	out := map[string]interface{}{}
+	for _, f := range g.fields {
+		out[f.key] = f.value
+	}
+	return out
+}
+
+// Stack returns the full call stack captured by this error, this will be nil
+// unless full stack capture was enabled when the error was traced.
+// see SetFullStack & FullStack
+func (g *Error) Stack() []*StackFrame {
+	if g.stack == nil {
+		return nil
+	}
+	frames := []*StackFrame{}
+	callersFrames := runtime.CallersFrames(g.stack)
+	for {
+		f, more := callersFrames.Next()
+		frames = append(frames, newStackFrameFromRuntime(f))
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+func (g *Error) hasStack() bool {
+	for e := g; e != nil; {
+		if e.stack != nil {
+			return true
+		}
+		next, ok := e.innerErr.(*Error)
+		if !ok {
+			break
+		}
+		e = next
+	}
+	return false
 }
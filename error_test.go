@@ -42,3 +42,23 @@ func TestErrorUnwrap(t *testing.T) {
 	e := goerr.New(innerErr)
 	assert.Equal(t, innerErr, e.Unwrap())
 }
+
+func TestErrorFields(t *testing.T) {
+	e := goerr.WrapWith(goerr.New("abc"), goerr.With("foo", "bar"), goerr.With("baz", 1))
+	assert.Equal(t, map[string]interface{}{"foo": "bar", "baz": 1}, e.Fields())
+	assert.Equal(t, e.Fields(), e.Frame().Context)
+	assert.Nil(t, goerr.New("abc").Fields())
+}
+
+func TestErrorFormat(t *testing.T) {
+	e := goerr.Wrap(goerr.New("abc"), "foo")
+	assert.Equal(t, "foo: abc", fmt.Sprintf("%v", e))
+	assert.Equal(t, "foo: abc", fmt.Sprintf("%s", e))
+	assert.Equal(t, `"foo: abc"`, fmt.Sprintf("%q", e))
+	assert.Equal(t, goerr.NewStackTrace(e).String(), fmt.Sprintf("%+v", e))
+	assert.Contains(t, fmt.Sprintf("%+v", e), "TestErrorFormat")
+	assert.Equal(t, e.GoString(), fmt.Sprintf("%#v", e))
+	assert.Contains(t, fmt.Sprintf("%#v", e), `&goerr.Error{message:"foo", frame:&goerr.StackFrame{`)
+	assert.Contains(t, fmt.Sprintf("%#v", e), `innerErr:&goerr.Error{message:"", frame:(*goerr.StackFrame)(nil)`)
+	assert.Equal(t, "%!d(*goerr.Error=foo: abc)", fmt.Sprintf("%d", e))
+}
@@ -0,0 +1,111 @@
+package goerr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"reflect"
+	"sync/atomic"
+)
+
+// FingerprintGranularity controls which parts of a StackTrace contribute to
+// its fingerprint, see StackTrace.Fingerprint.
+type FingerprintGranularity int32
+
+const (
+	// FingerprintLine uses the type of the cause, any message templates and
+	// the package, function, file name & line number of every wrapped frame.
+	FingerprintLine FingerprintGranularity = iota
+
+	// FingerprintFunction is the same as FingerprintLine without the file
+	// names & line numbers, so traces survive unrelated edits to a file.
+	FingerprintFunction
+
+	// FingerprintCause uses only the type of the cause, any message templates
+	// and the package & function of the deepest wrapped frame.
+	FingerprintCause
+)
+
+var fingerprintGranularity int32
+
+// SetFingerprintGranularity sets the granularity used by
+// StackTrace.Fingerprint for the entire program, the default is FingerprintLine.
+func SetFingerprintGranularity(g FingerprintGranularity) {
+	atomic.StoreInt32(&fingerprintGranularity, int32(g))
+}
+
+// Fingerprint returns a stable identity for the trace, such that the same
+// failure occurring many times can be grouped & deduplicated.
+//
+// Error messages & context values are ignored as they usually contain
+// variable parts, instead the type of the cause, the raw message templates
+// & the wrapped frames of the trace are hashed. Frames from a full stack
+// capture are excluded, as they vary with the entry point of the program
+// (such as the runtime & test harness) rather than the failure its self.
+// Files are identified by their package & file name, so the fingerprint
+// does not depend on where the program was built. see SetFingerprintGranularity
+func (s *StackTrace) Fingerprint() string {
+	return s.FingerprintWith(FingerprintGranularity(atomic.LoadInt32(&fingerprintGranularity)))
+}
+
+// FingerprintWith is the same as Fingerprint but with the given granularity.
+//
+// The fingerprint of a trace restored by ParseTrace is the one
+// calculated by the original program, regardless of granularity.
+func (s *StackTrace) FingerprintWith(g FingerprintGranularity) string {
+	if s.fingerprint != "" {
+		return s.fingerprint
+	}
+	h := sha256.New()
+	s.fingerprintTo(h, g)
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+func (s *StackTrace) fingerprintTo(w interface{ Write([]byte) (int, error) }, g FingerprintGranularity) {
+	fmt.Fprintf(w, "cause:%s\n", causeType(s.Cause))
+	for _, t := range s.Templates {
+		fmt.Fprintf(w, "tmpl:%s\n", t.Template)
+	}
+
+	frames := []*StackFrame{}
+	for _, f := range s.Stack {
+		if f.Wrapped {
+			frames = append(frames, f)
+		}
+	}
+	if g == FingerprintCause && len(frames) > 0 {
+		frames = frames[:1]
+	}
+	for _, f := range frames {
+		if g == FingerprintLine {
+			fmt.Fprintf(w, "frame:%s.%s:%s:%d\n", f.Package, f.Name, path.Join(f.Package, path.Base(f.File)), f.LineNumber)
+		} else {
+			fmt.Fprintf(w, "frame:%s.%s\n", f.Package, f.Name)
+		}
+	}
+
+	for i, b := range s.Branches {
+		fmt.Fprintf(w, "branch:%d\n", i)
+		b.fingerprintTo(w, g)
+	}
+}
+
+// causeType identifies the type of a cause, sentinels are identified by
+// their format as they all share the same type & remote errors by the
+// fingerprint of the original trace.
+func causeType(err error) string {
+	switch e := err.(type) {
+	case nil:
+		return ""
+	case *raisedError:
+		return "sentinel:" + e.sentinel.format
+	case *SentinelError:
+		return "sentinel:" + e.format
+	case *RemoteError:
+		if e.fingerprint != "" {
+			return "remote:" + e.fingerprint
+		}
+	}
+	return reflect.TypeOf(err).String()
+}
@@ -0,0 +1,86 @@
+package goerr_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func failWith(id int) error {
+	return goerr.Wrap(fmt.Errorf("user %d not found", id), fmt.Sprintf("loading %d", id))
+}
+
+func TestFingerprint(t *testing.T) {
+	a := goerr.NewStackTrace(failWith(1))
+	b := goerr.NewStackTrace(failWith(2))
+	assert.Equal(t, 16, len(a.Fingerprint()))
+	assert.Equal(t, a.Fingerprint(), b.Fingerprint())
+
+	c := goerr.NewStackTrace(goerr.Wrap(failWith(1)))
+	assert.NotEqual(t, a.Fingerprint(), c.Fingerprint())
+
+	d := goerr.NewStackTrace(goerr.Wrap(goerr.New("abc")))
+	assert.NotEqual(t, d.FingerprintWith(goerr.FingerprintLine), goerr.NewStackTrace(goerr.Wrap(goerr.New("abc"))).FingerprintWith(goerr.FingerprintLine))
+	assert.Equal(t, d.FingerprintWith(goerr.FingerprintFunction), goerr.NewStackTrace(goerr.Wrap(goerr.New("abc"))).FingerprintWith(goerr.FingerprintFunction))
+	assert.NotEqual(t, a.FingerprintWith(goerr.FingerprintFunction), c.FingerprintWith(goerr.FingerprintFunction))
+	assert.Equal(t, a.FingerprintWith(goerr.FingerprintCause), c.FingerprintWith(goerr.FingerprintCause))
+
+	goerr.SetFingerprintGranularity(goerr.FingerprintCause)
+	defer goerr.SetFingerprintGranularity(goerr.FingerprintLine)
+	assert.Equal(t, a.Fingerprint(), c.Fingerprint())
+}
+
+func fullStackFail() error {
+	return goerr.WrapWith(goerr.New("abc"), goerr.FullStack())
+}
+
+func TestFingerprintFullStack(t *testing.T) {
+	a := goerr.NewStackTrace(fullStackFail())
+	b := func() *goerr.StackTrace {
+		return goerr.NewStackTrace(fullStackFail())
+	}()
+	assert.NotEqual(t, len(a.Stack), len(b.Stack))
+	assert.Equal(t, a.Fingerprint(), b.Fingerprint())
+}
+
+func TestFingerprintSentinel(t *testing.T) {
+	errOther := goerr.Sentinel("user %s not found")
+	raise := func(s *goerr.SentinelError, id string) *goerr.StackTrace {
+		return goerr.NewStackTrace(s.With(id))
+	}
+	a := raise(errUserNotFound, "1")
+	assert.Equal(t, a.Fingerprint(), raise(errUserNotFound, "2").Fingerprint())
+	assert.Equal(t, a.Fingerprint(), raise(errOther, "1").Fingerprint())
+	assert.NotEqual(t, a.Fingerprint(), raise(goerr.Sentinel("other %s"), "1").Fingerprint())
+}
+
+func TestFingerprintJSON(t *testing.T) {
+	st := goerr.NewStackTrace(failWith(1))
+	j, err := json.Marshal(st)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(j), `"fingerprint":"`+st.Fingerprint()+`"`)
+		parsed, err := goerr.ParseTrace(j)
+		if assert.NoError(t, err) {
+			assert.Equal(t, st.Fingerprint(), parsed.Fingerprint())
+			prints := []string{}
+			for i := 0; i < 2; i++ {
+				prints = append(prints, goerr.NewStackTrace(goerr.Wrap(parsed.Error)).Fingerprint())
+			}
+			assert.Equal(t, prints[0], prints[1])
+			assert.NotEqual(t, st.Fingerprint(), prints[0])
+		}
+	}
+}
+
+func TestFingerprintTextRenderer(t *testing.T) {
+	err := failWith(1)
+	out := render(t, goerr.TextRenderer{Fingerprint: true}, err)
+	lines := strings.Split(out, "\n")
+	assert.Equal(t, "fingerprint: "+goerr.NewStackTrace(err).Fingerprint(), lines[1])
+	assert.Equal(t, "", lines[2])
+	assert.NotContains(t, render(t, goerr.TextRenderer{}, err), "fingerprint: ")
+}
@@ -4,8 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"runtime"
-	"strings"
+	"sync/atomic"
 )
 
 // PrintTrace will print the stack trace for the given error to STDERR.
@@ -23,8 +24,30 @@ import (
 //
 //  the-pkg-name.theMethodName:/the/file/path/to/go/src/file:123
 //  	if /the/file/path/to/go/src/file exists then this will be line 123
+//
+// see PrintTraceTo for other output formats & destinations.
 func PrintTrace(err error) {
-	fmt.Fprint(os.Stderr, NewStackTrace(err).String())
+	PrintTraceTo(os.Stderr, err)
+}
+
+// MaxStackDepth is the maximum number of frames that will be
+// captured when a full stack is recorded, see SetFullStack.
+var MaxStackDepth = 50
+
+var fullStack int32
+
+// SetFullStack turns full stack capture on or off for the entire program.
+//
+// By default only the frame of each Trace, Wrap or Check call is recorded.
+// With full stack capture enabled the deepest traced error in a chain will
+// also record the complete call stack (ala github.com/go-errors/errors) and
+// NewStackTrace will render every frame, highlighting the explicit wrap points.
+func SetFullStack(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&fullStack, v)
 }
 
 // Trace will take any value, convert it into an Error object,
@@ -34,6 +57,12 @@ func PrintTrace(err error) {
 // prefixed to the error text it's self to provide additional
 // context if required. These messages should be human friendly.
 func Trace(skip int, value interface{}, messages ...string) *Error {
+	return TraceWith(skip+1, value, Msg(messages...))
+}
+
+// TraceWith is the same as Trace but accepts a variadic number of Options
+// instead of messages, use Msg to supply the messages.
+func TraceWith(skip int, value interface{}, opts ...Option) *Error {
 	err, ok := value.(*Error)
 	if !ok {
 		err = New(value)
@@ -44,11 +73,22 @@ func Trace(skip int, value interface{}, messages ...string) *Error {
 		panic("goerr failed to trace runtime.Caller(skip + 1)")
 	}
 
-	return &Error{
+	traced := &Error{
 		innerErr: err,
 		caller:   pc,
-		message:  strings.Join(messages, ": "),
 	}
+
+	for _, opt := range opts {
+		opt(traced)
+	}
+
+	if traced.fullStack || atomic.LoadInt32(&fullStack) == 1 {
+		if !err.hasStack() {
+			traced.stack = callers(skip + 1)
+		}
+	}
+
+	return traced
 }
 
 // Wrap is simply a shortcut for Trace(0, err, "some message")
@@ -56,6 +96,17 @@ func Wrap(value interface{}, messages ...string) *Error {
 	return Trace(1, value, messages...)
 }
 
+// WrapWith is simply a shortcut for TraceWith(0, err, opts...)
+func WrapWith(value interface{}, opts ...Option) *Error {
+	return TraceWith(1, value, opts...)
+}
+
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, MaxStackDepth)
+	n := runtime.Callers(skip+2, pcs)
+	return pcs[:n]
+}
+
 // Unwrap returns the result of calling the Unwrap method on err, if err's
 // type contains an Unwrap method returning error.
 // Otherwise, Unwrap returns nil.
@@ -69,6 +120,9 @@ func Unwrap(err error) error {
 
 // Cause will unwrap the entire error chain until the root error is found.
 // ie: the cause.
+//
+// If an error in the chain wraps many errors, such as a *MultiError,
+// then that error is returned as the cause. see Causes
 func Cause(err error) error {
 	e := err
 	for {
@@ -81,6 +135,92 @@ func Cause(err error) error {
 	return e
 }
 
+// TypeName returns a name for the kind of failure err represents, suitable
+// for grouping errors in tools such as OpenTelemetry or Sentry.
+//
+// The cause of a goerr error is often a plain error, such as one from
+// errors.New, or an error of this package whose type says nothing about
+// the failure. Instead the first of these that applies is used:
+//
+//   - the type of the outer most error in the chain that is neither from
+//     goerr nor a plain error, eg: *fs.PathError
+//   - the unfilled format of the SentinelError that was raised
+//   - the name of the Code of the error, eg: NotFound
+//   - the type of the cause, eg: *errors.errorString
+//
+// The chain ends at any error that wraps many errors, such as a *MultiError,
+// the errors it wraps are not considered.
+func TypeName(err error) string {
+	var sentinel *SentinelError
+	code := OK
+	for e := err; e != nil; e = Unwrap(e) {
+		if _, ok := e.(interface{ Unwrap() []error }); ok {
+			break
+		}
+		switch t := e.(type) {
+		case *raisedError:
+			if sentinel == nil {
+				sentinel = t.sentinel
+			}
+		case *SentinelError:
+			if sentinel == nil {
+				sentinel = t
+			}
+		}
+		if coder, ok := e.(interface{ Code() Code }); ok && code == OK {
+			code = coder.Code()
+		}
+		t := reflect.TypeOf(e)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.PkgPath() == goerrPkg || plainErrors[fmt.Sprintf("%T", e)] {
+			continue
+		}
+		return fmt.Sprintf("%T", e)
+	}
+	if sentinel != nil {
+		return sentinel.Error()
+	}
+	if code != OK {
+		return code.String()
+	}
+	return fmt.Sprintf("%T", Cause(err))
+}
+
+// goerrPkg is the import path of this package, its errors are skipped by TypeName.
+var goerrPkg = reflect.TypeOf(Error{}).PkgPath()
+
+// plainErrors are the types of errors from the standard library that
+// only carry a message, they are skipped by TypeName.
+var plainErrors = map[string]bool{
+	"*errors.errorString": true,
+	"*fmt.wrapError":      true,
+	"*fmt.wrapErrors":     true,
+	"*errors.joinError":   true,
+}
+
+// walk calls fn for each error in err's tree, depth first, including the
+// branches of any errors that wrap many errors. Walking stops when fn
+// returns false, walk returns false if it was stopped.
+func walk(err error, fn func(error) bool) bool {
+	for err != nil {
+		if !fn(err) {
+			return false
+		}
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range multi.Unwrap() {
+				if !walk(e, fn) {
+					return false
+				}
+			}
+			return true
+		}
+		err = Unwrap(err)
+	}
+	return true
+}
+
 // Is reports whether any error in err's chain matches target.
 //
 // The chain consists of err itself followed by the sequence of errors obtained by
@@ -133,24 +273,62 @@ func As(err error, target interface{}) bool {
 //
 // YMMV - It mimics the goV2 check/handle proposal: https://bit.ly/354fRXv
 func Check(err error, messages ...string) {
+	check(1, err, messages)
+}
+
+func check(skip int, err error, messages []string) {
 	if err != nil {
-		panic(Trace(1, err, messages...))
+		e := Trace(skip+1, err, messages...)
+		e.checked = true
+		panic(e)
 	}
 }
 
 // Handle will recover, cast the result into an error
 // and then call the provided onError handler.
 //
+// The error is traced to the frame that panicked, see Recovered.
+//
 // Goes without saying but for this to be useful
 // you must preface it with `defer`.
 //
 // YMMV - It mimics the goV2 check/handle proposal: https://bit.ly/354fRXv
 func Handle(onError func(err error)) {
 	if r := recover(); r != nil {
-		e, ok := r.(error)
-		if !ok {
-			e = fmt.Errorf("%v", r)
+		onError(recovered(r, panicStack(1), atomic.LoadInt32(&fullStack) == 1))
+	}
+}
+
+// HandleCheck is the same as Handle except that it will only recover panics
+// raised by Check, any other panic is re-panicked with its original value.
+//
+// This ensures real bugs, such as a nil pointer dereference, are not
+// silently turned into ordinary errors.
+func HandleCheck(onError func(err error)) {
+	if r := recover(); r != nil {
+		if e, ok := r.(*Error); ok && e.checked {
+			onError(recovered(e, panicStack(1), false))
+			return
+		}
+		panic(r)
+	}
+}
+
+// HandleRuntime is the same as HandleCheck except that it will also recover
+// panics caused by a runtime.Error, such as a nil pointer dereference.
+//
+// The full stack of the goroutine is captured at the time of the panic and
+// the error will be traced to the frame that panicked.
+func HandleRuntime(onError func(err error)) {
+	if r := recover(); r != nil {
+		if e, ok := r.(*Error); ok && e.checked {
+			onError(recovered(e, panicStack(1), false))
+			return
+		}
+		if re, ok := r.(runtime.Error); ok {
+			onError(recovered(re, panicStack(1), true))
+			return
 		}
-		onError(Trace(4, e))
+		panic(r)
 	}
 }
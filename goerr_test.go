@@ -1,7 +1,10 @@
 package goerr_test
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"runtime"
 	"testing"
 
 	"github.com/brad-jones/goerr/v2"
@@ -21,6 +24,20 @@ func TestCause(t *testing.T) {
 	assert.Equal(t, e1, goerr.Cause(e3))
 }
 
+func TestTypeName(t *testing.T) {
+	_, err := os.Open("/does/not/exist")
+	assert.Equal(t, "*fs.PathError", goerr.TypeName(goerr.Wrap(fmt.Errorf("opening: %w", err))))
+
+	errNotFound := goerr.Sentinel("user %s not found", goerr.WithCode(goerr.NotFound))
+	assert.Equal(t, "user %s not found", goerr.TypeName(goerr.Wrap(errNotFound.With("123"))))
+
+	assert.Equal(t, "Unavailable", goerr.TypeName(goerr.WrapWith(errors.New("abc"), goerr.WithCode(goerr.Unavailable))))
+	assert.Equal(t, "*errors.errorString", goerr.TypeName(goerr.Wrap(errors.New("abc"))))
+	assert.Equal(t, "*goerr.MultiError", goerr.TypeName(goerr.Join(errors.New("abc"), errors.New("def"))))
+	assert.Equal(t, "*goerr.MultiError", goerr.TypeName(goerr.Join(errNotFound.With("123"), errors.New("def"))))
+	assert.Equal(t, "Internal", goerr.TypeName(goerr.WrapWith(goerr.Join(errNotFound.With("123"), errors.New("def")), goerr.WithCode(goerr.Internal))))
+}
+
 func TestIs(t *testing.T) {
 	e1 := goerr.New("abc")
 	e2 := goerr.Wrap(e1)
@@ -37,3 +54,103 @@ func TestAs(t *testing.T) {
 	assert.Equal(t, true, result)
 	assert.Equal(t, e2, err)
 }
+
+func TestSetFullStack(t *testing.T) {
+	goerr.SetFullStack(true)
+	defer goerr.SetFullStack(false)
+	e1 := goerr.Wrap(goerr.New("abc"))
+	e2 := goerr.Wrap(e1)
+	assert.NotNil(t, e1.Stack())
+	assert.Nil(t, e2.Stack())
+}
+
+func TestWrapWith(t *testing.T) {
+	e := goerr.WrapWith(goerr.New("abc"), goerr.Msg("foo", "bar"))
+	assert.Equal(t, "foo: bar: abc", e.Error())
+	assert.Nil(t, e.Stack())
+	assert.Equal(t, "TestWrapWith", e.Frame().Name)
+}
+
+func checkFails() (err error) {
+	defer goerr.HandleCheck(func(e error) { err = e })
+	goerr.Check(goerr.New("abc"), "checked")
+	return nil
+}
+
+func TestHandleCheck(t *testing.T) {
+	err := checkFails()
+	assert.EqualError(t, err, "checked: abc")
+}
+
+func TestHandleCheckRepanics(t *testing.T) {
+	original := goerr.New("not from check")
+	assert.PanicsWithValue(t, original, func() {
+		defer goerr.HandleCheck(func(e error) {})
+		panic(original)
+	})
+	assert.PanicsWithValue(t, "boom", func() {
+		defer goerr.HandleCheck(func(e error) {})
+		panic("boom")
+	})
+}
+
+func nilDeref() (err error) {
+	defer goerr.HandleRuntime(func(e error) { err = e })
+	var foo *Foo
+	fmt.Println(foo.Bar)
+	return nil
+}
+
+func TestHandleRuntime(t *testing.T) {
+	err := nilDeref()
+	var re runtime.Error
+	if assert.True(t, goerr.As(err, &re)) {
+		assert.Contains(t, re.Error(), "nil pointer dereference")
+	}
+	var e *goerr.Error
+	if assert.True(t, goerr.As(err, &e)) {
+		assert.Equal(t, "nilDeref", e.Frame().Name)
+		line, _ := e.Frame().SourceLine()
+		assert.Equal(t, "fmt.Println(foo.Bar)", line)
+		stack := e.Stack()
+		if assert.True(t, len(stack) > 2) {
+			assert.Equal(t, "nilDeref", stack[0].Name)
+			assert.Equal(t, "TestHandleRuntime", stack[1].Name)
+		}
+	}
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer goerr.HandleRuntime(func(e error) {})
+		panic("boom")
+	})
+}
+
+func panics() (err error) {
+	defer goerr.Handle(func(e error) { err = e })
+	panic("boom")
+}
+
+func checkPanics() (err error) {
+	defer goerr.Handle(func(e error) { err = e })
+	goerr.Check(goerr.New("abc"), "checked")
+	return nil
+}
+
+func TestHandle(t *testing.T) {
+	var e *goerr.Error
+	if assert.True(t, goerr.As(panics(), &e)) {
+		assert.EqualError(t, e, "boom")
+		assert.Equal(t, goerr.ForeignPanic, e.Panic())
+		assert.Equal(t, "panics", e.Frame().Name)
+		line, _ := e.PanicFrame().SourceLine()
+		assert.Equal(t, `panic("boom")`, line)
+	}
+
+	if assert.True(t, goerr.As(checkPanics(), &e)) {
+		assert.EqualError(t, e, "checked: abc")
+		assert.Equal(t, goerr.CheckPanic, e.Panic())
+		assert.Equal(t, "TestHandle", e.Frame().Name)
+		line, _ := e.PanicFrame().SourceLine()
+		assert.Equal(t, `goerr.Check(goerr.New("abc"), "checked")`, line)
+	}
+}
@@ -0,0 +1,87 @@
+package goerr
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Group runs functions in their own goroutines and collects their errors,
+// the zero value is ready to use.
+//
+// Any error returned by a function, or any panic (such as those raised by
+// Check), is converted into a traced *Error that also includes the frame of
+// the site that spawned the goroutine. Without this a panic in a goroutine
+// would crash the entire program as it can not be recovered by the spawner.
+type Group struct {
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	results []error
+}
+
+// Go is a shortcut for creating a new Group and calling Go on it.
+//
+// For example:
+//
+//	g := goerr.Go(func() error {
+//		Check(build("foo"))
+//		return nil
+//	})
+//	g.Go(func() error {
+//		return build("bar")
+//	})
+//	err := g.Wait()
+func Go(fn func() error) *Group {
+	g := &Group{}
+	g.spawn(fn)
+	return g
+}
+
+// Go runs fn in a new goroutine.
+func (g *Group) Go(fn func() error) {
+	g.spawn(fn)
+}
+
+// Wait blocks until all functions have returned and then returns their
+// errors, in the order the functions were given, joined as per Join.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return Join(g.results...)
+}
+
+// spawn must be called directly by Go or Group.Go so that
+// the site that called them is recorded as the spawn site.
+func (g *Group) spawn(fn func() error) {
+	pc, _, _, _ := runtime.Caller(2)
+
+	g.mu.Lock()
+	i := len(g.results)
+	g.results = append(g.results, nil)
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		err := g.run(fn)
+		if err == nil {
+			return
+		}
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.results[i] = &Error{innerErr: err, caller: pc}
+	}()
+}
+
+func (g *Group) run(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*Error)
+			if !ok || !e.checked {
+				e = Recovered(r)
+			}
+			err = e
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,72 @@
+package goerr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	e1 := errors.New("abc")
+	g := goerr.Go(func() error {
+		return nil
+	})
+	g.Go(func() error {
+		goerr.Check(e1, "checked")
+		return nil
+	})
+	g.Go(func() error {
+		panic("boom")
+	})
+	g.Go(func() error {
+		return e1
+	})
+
+	err := g.Wait()
+	var multi *goerr.MultiError
+	if assert.True(t, goerr.As(err, &multi)) && assert.Equal(t, 3, len(multi.Errors())) {
+		assert.Equal(t, "checked: abc", multi.Errors()[0].Error())
+		assert.Equal(t, "boom", multi.Errors()[1].Error())
+		assert.Equal(t, "abc", multi.Errors()[2].Error())
+	}
+	assert.True(t, goerr.Is(err, e1))
+
+	st := goerr.NewStackTrace(err)
+	if assert.Equal(t, 3, len(st.Branches)) {
+		checked := st.Branches[0].Stack
+		if assert.Equal(t, 2, len(checked)) {
+			assert.Equal(t, "TestGroup.func2", checked[0].Name)
+			assert.Equal(t, "TestGroup", checked[1].Name)
+			line, _ := checked[1].SourceLine()
+			assert.Equal(t, "g.Go(func() error {", line)
+		}
+		panicked := st.Branches[1].Stack
+		if assert.Equal(t, 2, len(panicked)) {
+			assert.Equal(t, "TestGroup.func3", panicked[0].Name)
+			line, _ := panicked[0].SourceLine()
+			assert.Equal(t, `panic("boom")`, line)
+			assert.Equal(t, "TestGroup", panicked[1].Name)
+		}
+		returned := st.Branches[2].Stack
+		if assert.Equal(t, 1, len(returned)) {
+			assert.Equal(t, "TestGroup", returned[0].Name)
+		}
+	}
+}
+
+func TestGroupNoErrors(t *testing.T) {
+	g := &goerr.Group{}
+	g.Go(func() error { return nil })
+	assert.Nil(t, g.Wait())
+	assert.Nil(t, g.Wait())
+}
+
+func TestGoSpawnSite(t *testing.T) {
+	err := goerr.Go(func() error { return errors.New("abc") }).Wait()
+	st := goerr.NewStackTrace(err)
+	if assert.Equal(t, 1, len(st.Stack)) {
+		assert.Equal(t, "TestGoSpawnSite", st.Stack[0].Name)
+	}
+}
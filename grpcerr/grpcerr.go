@@ -0,0 +1,197 @@
+/*
+Package grpcerr converts goerr errors to and from gRPC statuses.
+
+A *goerr.Error returned from a gRPC handler would normally reach the client
+as codes.Unknown with a flattened message. ToStatus instead maps the
+goerr.Code of the error chain to the equivalent gRPC code and attaches the
+context values as an ErrorInfo detail, FromStatus reverses the process on the
+client side. The frames of the error are only sent, in a DebugInfo detail,
+when requested with WithDebugInfo as they should not usually be exposed to
+clients:
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(grpcerr.UnaryServerInterceptor(grpcerr.WithDebugInfo())),
+	)
+
+The interceptors do this automatically:
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(grpcerr.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(grpcerr.StreamServerInterceptor()),
+	)
+
+	conn, err := grpc.NewClient(addr,
+		grpc.WithUnaryInterceptor(grpcerr.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(grpcerr.StreamClientInterceptor()),
+	)
+*/
+package grpcerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/brad-jones/goerr/v2"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain is the ErrorInfo domain used to identify statuses created by ToStatus.
+const Domain = "github.com/brad-jones/goerr"
+
+// Option configures ToStatus & the interceptors.
+type Option func(*options)
+
+type options struct {
+	debugInfo bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithDebugInfo sends the stack frames & complete trace of an error in a
+// DebugInfo detail, such that FromStatus can restore the original trace.
+// Only use this when traces may be exposed to clients.
+func WithDebugInfo() Option {
+	return func(o *options) {
+		o.debugInfo = true
+	}
+}
+
+// ToStatus converts an error into a gRPC status.
+//
+// The goerr.Code of the error chain is used as the status code, unless the
+// chain has no code and already contains a gRPC status. The context values
+// of the error are attached in an ErrorInfo detail, with the name of the
+// code in UPPER_SNAKE_CASE as the reason. See WithDebugInfo to also attach
+// the complete trace, as per goerr.StackTrace.MarshalJSON.
+//
+// The context values are redacted & made safe to encode, as per
+// goerr.StackTrace.SafeCtx, values that are not strings are sent as JSON.
+func ToStatus(err error, opts ...Option) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+
+	code := goerr.CodeOf(err)
+	if code == goerr.Unknown {
+		if s, ok := status.FromError(err); ok {
+			return s
+		}
+	}
+
+	o := newOptions(opts)
+	trace := goerr.NewStackTrace(err)
+	s := status.New(codes.Code(code), trace.ErrorMsg)
+
+	info := &errdetails.ErrorInfo{
+		Reason:   reason(code),
+		Domain:   Domain,
+		Metadata: map[string]string{},
+	}
+	for k, v := range trace.SafeCtx() {
+		if str, ok := v.(string); ok {
+			info.Metadata[k] = str
+			continue
+		}
+		value, _ := json.Marshal(v)
+		info.Metadata[k] = string(value)
+	}
+	if withInfo, err := s.WithDetails(info); err == nil {
+		s = withInfo
+	}
+
+	if o.debugInfo {
+		j, err := json.Marshal(trace)
+		if err != nil {
+			return s
+		}
+		debug := &errdetails.DebugInfo{Detail: string(j)}
+		for _, f := range trace.Stack {
+			debug.StackEntries = append(debug.StackEntries,
+				fmt.Sprintf("%s.%s:%s:%d", f.Package, f.Name, f.File, f.LineNumber),
+			)
+		}
+		if withDebug, err := s.WithDetails(debug); err == nil {
+			s = withDebug
+		}
+	}
+
+	return s
+}
+
+// FromStatus converts a gRPC status back into an error.
+//
+// If the status contains the DebugInfo detail created by ToStatus the
+// original trace is restored and a *goerr.RemoteError is returned, otherwise
+// a *goerr.Error with the equivalent goerr.Code & the ErrorInfo metadata as
+// context is returned. ErrorInfo details of other domains are ignored.
+// nil is returned for an OK status.
+func FromStatus(s *status.Status) error {
+	if s == nil || s.Code() == codes.OK {
+		return nil
+	}
+
+	var info *errdetails.ErrorInfo
+	for _, d := range s.Details() {
+		switch detail := d.(type) {
+		case *errdetails.DebugInfo:
+			if detail.Detail != "" {
+				if trace, err := goerr.ParseTrace([]byte(detail.Detail)); err == nil {
+					return trace.Error
+				}
+			}
+		case *errdetails.ErrorInfo:
+			if detail.Domain == Domain {
+				info = detail
+			}
+		}
+	}
+
+	opts := []goerr.Option{goerr.WithCode(goerr.Code(s.Code()))}
+	if info != nil {
+		for k, v := range info.Metadata {
+			opts = append(opts, goerr.With(k, v))
+		}
+	}
+	return goerr.NewWith(s.Message(), opts...)
+}
+
+// reason converts the name of a code into UPPER_SNAKE_CASE as is expected
+// of an ErrorInfo reason, eg: NotFound becomes NOT_FOUND & Code(99) CODE_99
+func reason(code goerr.Code) string {
+	sb := &strings.Builder{}
+	var prev rune
+	for _, r := range code.String() {
+		switch {
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			sb.WriteRune('_')
+			sb.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(unicode.ToUpper(r))
+		case prev != 0 && prev != '_':
+			r = '_'
+			sb.WriteRune(r)
+		}
+		prev = r
+	}
+	return strings.TrimSuffix(sb.String(), "_")
+}
+
+// FromError converts an error returned by a gRPC client back into a goerr
+// error, see FromStatus. Errors that are not gRPC statuses are returned as is.
+func FromError(err error) error {
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return FromStatus(s)
+}
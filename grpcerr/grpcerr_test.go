@@ -0,0 +1,141 @@
+package grpcerr_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/brad-jones/goerr/v2/grpcerr"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var errNotFound = goerr.NewWith("service not found", goerr.WithCode(goerr.NotFound))
+
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+func (s *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	return nil, goerr.WrapWith(errNotFound, goerr.With("service", req.Service))
+}
+
+func (s *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	return goerr.WrapWith(errNotFound, goerr.With("service", req.Service))
+}
+
+func newClient(t *testing.T, serverOpts []grpcerr.Option) grpc_health_v1.HealthClient {
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(grpcerr.UnaryServerInterceptor(serverOpts...)),
+		grpc.StreamInterceptor(grpcerr.StreamServerInterceptor(serverOpts...)),
+	)
+	grpc_health_v1.RegisterHealthServer(server, &healthServer{})
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(grpcerr.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(grpcerr.StreamClientInterceptor()),
+	)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { conn.Close() })
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestToStatus(t *testing.T) {
+	s := grpcerr.ToStatus(goerr.WrapWith(errNotFound, goerr.With("service", "foo")), grpcerr.WithDebugInfo())
+	assert.Equal(t, codes.NotFound, s.Code())
+	assert.Equal(t, "service not found", s.Message())
+	if assert.Equal(t, 2, len(s.Details())) {
+		info := s.Details()[0].(*errdetails.ErrorInfo)
+		assert.Equal(t, "NOT_FOUND", info.Reason)
+		assert.Equal(t, map[string]string{"service": "foo"}, info.Metadata)
+		debug := s.Details()[1].(*errdetails.DebugInfo)
+		if assert.Equal(t, 1, len(debug.StackEntries)) {
+			assert.Contains(t, debug.StackEntries[0], "grpcerr_test.TestToStatus:")
+		}
+	}
+
+	s = grpcerr.ToStatus(goerr.Wrap(errNotFound))
+	assert.Equal(t, 1, len(s.Details()))
+
+	goerr.RedactKeys("password")
+	defer goerr.ResetRedaction()
+	s = grpcerr.ToStatus(goerr.WrapWith(goerr.New("abc"),
+		goerr.WithCode(goerr.InvalidArgument),
+		goerr.With("password", "hunter2"),
+		goerr.With("ids", []int{1, 2}),
+		goerr.With("big", strings.Repeat("a", 2000)),
+	))
+	if assert.Equal(t, 1, len(s.Details())) {
+		info := s.Details()[0].(*errdetails.ErrorInfo)
+		assert.Equal(t, "INVALID_ARGUMENT", info.Reason)
+		assert.Equal(t, "[REDACTED]", info.Metadata["password"])
+		assert.Equal(t, "[1,2]", info.Metadata["ids"])
+		assert.True(t, len(info.Metadata["big"]) < 2000)
+	}
+
+	s = grpcerr.ToStatus(goerr.NewWith("abc", goerr.WithCode(goerr.Code(99))))
+	assert.Equal(t, "CODE_99", s.Details()[0].(*errdetails.ErrorInfo).Reason)
+
+	s = grpcerr.ToStatus(status.Error(codes.Aborted, "abc"))
+	assert.Equal(t, codes.Aborted, s.Code())
+	assert.Equal(t, "abc", s.Message())
+}
+
+func TestFromStatus(t *testing.T) {
+	assert.Nil(t, grpcerr.FromStatus(status.New(codes.OK, "")))
+
+	err := grpcerr.FromStatus(status.New(codes.Unavailable, "abc"))
+	assert.Equal(t, goerr.Unavailable, goerr.CodeOf(err))
+	assert.Equal(t, "abc", err.Error())
+
+	s, _ := status.New(codes.NotFound, "abc").WithDetails(&errdetails.ErrorInfo{Domain: grpcerr.Domain, Metadata: map[string]string{"foo": "bar"}})
+	err = grpcerr.FromStatus(s)
+	assert.Equal(t, map[string]interface{}{"foo": "bar"}, goerr.NewStackTrace(err).ErrorCtx)
+
+	s, _ = status.New(codes.NotFound, "abc").WithDetails(&errdetails.ErrorInfo{Domain: "googleapis.com", Metadata: map[string]string{"foo": "bar"}})
+	err = grpcerr.FromStatus(s)
+	assert.Nil(t, goerr.NewStackTrace(err).ErrorCtx)
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	client := newClient(t, []grpcerr.Option{grpcerr.WithDebugInfo()})
+	_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "foo"})
+	assert.Equal(t, goerr.NotFound, goerr.CodeOf(err))
+
+	var remote *goerr.RemoteError
+	if assert.True(t, goerr.As(err, &remote)) {
+		assert.Equal(t, "service not found", remote.Error())
+		assert.Equal(t, map[string]interface{}{"service": "foo"}, remote.Ctx())
+		if assert.Equal(t, 1, len(remote.Frames())) {
+			assert.Equal(t, "(*healthServer).Check", remote.Frames()[0].Name)
+		}
+	}
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	client := newClient(t, nil)
+	stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "foo"})
+	if assert.NoError(t, err) {
+		_, err = stream.Recv()
+		assert.Equal(t, goerr.NotFound, goerr.CodeOf(err))
+		assert.Equal(t, "service not found", err.Error())
+		assert.Equal(t, map[string]interface{}{"service": "foo"}, goerr.NewStackTrace(err).ErrorCtx)
+	}
+}
@@ -0,0 +1,70 @@
+package grpcerr
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+)
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that
+// converts any error returned by a handler into a status with ToStatus.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, ToStatus(err, opts...).Err()
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// converts any error returned by a handler into a status with ToStatus.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return ToStatus(err, opts...).Err()
+		}
+		return nil
+	}
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that
+// converts any status returned by a call back into an error with FromError.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return FromError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor that
+// converts any status returned by a stream back into an error with FromError.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, FromError(err)
+		}
+		return &clientStream{cs}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	return convertStreamErr(s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return convertStreamErr(s.ClientStream.RecvMsg(m))
+}
+
+func convertStreamErr(err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	return FromError(err)
+}
@@ -0,0 +1,165 @@
+/*
+Package httperr provides net/http middleware that recovers panics, such as
+those raised by goerr.Check, and renders them as goerr traces.
+
+	http.ListenAndServe(":8080", httperr.Middleware(mux))
+
+In production an RFC 7807 problem+json response is written, in development
+mode the complete trace is written instead:
+
+	http.ListenAndServe(":8080", httperr.Middleware(mux, httperr.DevMode()))
+*/
+package httperr
+
+import (
+	"bufio"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/brad-jones/goerr/v2"
+)
+
+// Responder writes the response for a recovered error.
+type Responder func(w http.ResponseWriter, r *http.Request, st *goerr.StackTrace)
+
+// Option configures the Middleware.
+type Option func(*config)
+
+type config struct {
+	logger    *slog.Logger
+	responder Responder
+}
+
+// WithLogger sets the logger that recovered errors are logged to,
+// by default slog.Default() is used.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// WithResponder sets the Responder used to write the response
+// for a recovered error, by default ProblemResponder is used.
+func WithResponder(responder Responder) Option {
+	return func(c *config) {
+		c.responder = responder
+	}
+}
+
+// DevMode writes the complete trace of any recovered error in the response,
+// this is the same as WithResponder(TraceResponder).
+func DevMode() Option {
+	return WithResponder(TraceResponder)
+}
+
+// Middleware returns a http.Handler that recovers any panic from next,
+// converts it into a *goerr.Error with goerr.Trace, logs the trace and
+// then writes a response with the configured Responder.
+//
+// The status of the response is derived from the goerr.Code of the error,
+// see goerr.Code.HTTPStatus. Panics with http.ErrAbortHandler are re-panicked
+// as per the net/http convention.
+func Middleware(next http.Handler, opts ...Option) http.Handler {
+	c := &config{responder: ProblemResponder}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			st := goerr.NewStackTrace(goerr.Recovered(v))
+
+			logger := c.logger
+			if logger == nil {
+				logger = slog.Default()
+			}
+			logger.ErrorContext(r.Context(), "recovered from panic",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"err", st,
+			)
+
+			if !rw.wroteHeader {
+				c.responder(w, r, st)
+			}
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// ProblemResponder writes an RFC 7807 application/problem+json
+// response that does not include any details of the error its self.
+// see goerr.NewProblemDetails
+func ProblemResponder(w http.ResponseWriter, r *http.Request, st *goerr.StackTrace) {
+	goerr.NewProblemDetails(st.Error,
+		goerr.RedactDetail(),
+		goerr.RedactCtx(),
+		goerr.RedactFrames(),
+		goerr.ProblemInstance(r.URL.Path),
+	).Respond(w)
+}
+
+// TraceResponder writes the complete trace of the error as plain text,
+// this should only be used in development.
+func TraceResponder(w http.ResponseWriter, r *http.Request, st *goerr.StackTrace) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(goerr.CodeOf(st.Error).HTTPStatus())
+	(goerr.TextRenderer{}).Render(w, st)
+}
+
+// responseWriter records if the wrapped handler has already started
+// writing a response, in which case no error response can be written.
+//
+// The optional interfaces of the underlying http.ResponseWriter are passed
+// through, either directly or by way of Unwrap for http.ResponseController.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (rw *responseWriter) WriteHeader(status int) {
+	rw.wroteHeader = true
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	rw.wroteHeader = true
+	return io.Copy(rw.ResponseWriter, r)
+}
+
+func (rw *responseWriter) Flush() {
+	rw.FlushError()
+}
+
+func (rw *responseWriter) FlushError() error {
+	rw.wroteHeader = true
+	return http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := http.NewResponseController(rw.ResponseWriter).Hijack()
+	if err == nil {
+		rw.wroteHeader = true
+	}
+	return conn, buf, err
+}
+
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,140 @@
+package httperr_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/brad-jones/goerr/v2/httperr"
+	"github.com/stretchr/testify/assert"
+)
+
+var errNotFound = goerr.NewWith("user not found", goerr.WithCode(goerr.NotFound))
+
+func checkHandler(w http.ResponseWriter, r *http.Request) {
+	goerr.Check(errNotFound, "loading user")
+}
+
+func serve(h http.Handler, opts ...httperr.Option) (*httptest.ResponseRecorder, string) {
+	logs := &bytes.Buffer{}
+	opts = append([]httperr.Option{httperr.WithLogger(slog.New(slog.NewJSONHandler(logs, nil)))}, opts...)
+	rec := httptest.NewRecorder()
+	httperr.Middleware(h, opts...).ServeHTTP(rec, httptest.NewRequest("GET", "/users/1", nil))
+	return rec, logs.String()
+}
+
+func TestMiddlewareProblem(t *testing.T) {
+	rec, logs := serve(http.HandlerFunc(checkHandler))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	var problem map[string]interface{}
+	if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem)) {
+		assert.Equal(t, "Not Found", problem["title"])
+		assert.Equal(t, float64(404), problem["status"])
+		assert.Equal(t, "/users/1", problem["instance"])
+	}
+	assert.Contains(t, logs, `"error-msg":"loading user: user not found"`)
+	assert.Contains(t, logs, "httperr_test.checkHandler:")
+}
+
+func TestMiddlewareDevMode(t *testing.T) {
+	rec, _ := serve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(errors.New("boom"))
+	}), httperr.DevMode())
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "boom (recovered from panic)\n\n"))
+	assert.Contains(t, rec.Body.String(), "TestMiddlewareDevMode")
+}
+
+func panicHandler(w http.ResponseWriter, r *http.Request) {
+	var m map[string]int
+	m["boom"]++
+}
+
+func TestMiddlewarePanicFrame(t *testing.T) {
+	rec, _ := serve(http.HandlerFunc(panicHandler), httperr.DevMode())
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "httperr_test.panicHandler:")
+	assert.Contains(t, rec.Body.String(), "\tm[\"boom\"]++\n")
+}
+
+func TestMiddlewareHeadersWritten(t *testing.T) {
+	rec, logs := serve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("boom")
+	}))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Contains(t, logs, `"error-msg":"boom"`)
+}
+
+func TestMiddlewareNoPanic(t *testing.T) {
+	rec, logs := serve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok", rec.Body.String())
+	assert.Equal(t, "", logs)
+}
+
+func TestMiddlewareFlush(t *testing.T) {
+	rec, logs := serve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		panic("boom")
+	}))
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "", rec.Body.String())
+	assert.Contains(t, logs, `"error-msg":"boom"`)
+}
+
+func TestMiddlewareReadFrom(t *testing.T) {
+	rec, _ := serve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, strings.NewReader("ok"))
+		panic("boom")
+	}))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok", rec.Body.String())
+}
+
+func TestMiddlewareResponseController(t *testing.T) {
+	rec, _ := serve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, http.NewResponseController(w).Flush())
+		_, _, err := http.NewResponseController(w).Hijack()
+		assert.True(t, errors.Is(err, http.ErrNotSupported))
+	}))
+	assert.True(t, rec.Flushed)
+}
+
+func TestMiddlewareHijack(t *testing.T) {
+	srv := httptest.NewServer(httperr.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 204 No Content\r\nConnection: close\r\n\r\n")
+		buf.Flush()
+	})))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL)
+	if assert.NoError(t, err) {
+		res.Body.Close()
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	}
+}
+
+func TestMiddlewareAbortHandler(t *testing.T) {
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		serve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+	})
+}
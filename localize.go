@@ -0,0 +1,171 @@
+package goerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Localizer renders message templates for a particular locale.
+type Localizer interface {
+	// Localize renders template with params for locale,
+	// false is returned if there is no translation.
+	Localize(locale, template string, params Params) (string, bool)
+}
+
+// Localize returns the message of err rendered for locale.
+//
+// The message of each error in the chain with a Template is rendered with l,
+// falling back to the template its self when l has no translation. This
+// includes the templates of a *RemoteError and errors wrapped by other
+// packages, such as with fmt.Errorf("...: %w", err), in which case the
+// message of the wrapped error is replaced within the outer message.
+// Messages set with Msg are output as is.
+func Localize(err error, locale string, l Localizer) string {
+	if err == nil {
+		return ""
+	}
+	switch e := err.(type) {
+	case *Error:
+		msg := e.message
+		if e.template != nil {
+			if localized, ok := l.Localize(locale, e.template.Template, e.template.Params); ok {
+				msg = localized
+			}
+		}
+		inner := Localize(e.innerErr, locale, l)
+		if msg == "" {
+			return inner
+		}
+		return msg + ": " + inner
+	case *RemoteError:
+		msg := e.message
+		for _, t := range e.templates {
+			if localized, ok := l.Localize(locale, t.Template, t.Params); ok {
+				msg = strings.Replace(msg, RenderTemplate(t.Template, t.Params), localized, 1)
+			}
+		}
+		return localizeWrapped(msg, e.errs, locale, l)
+	case interface{ Unwrap() []error }:
+		return localizeWrapped(err.Error(), e.Unwrap(), locale, l)
+	case interface{ Unwrap() error }:
+		return localizeWrapped(err.Error(), []error{e.Unwrap()}, locale, l)
+	}
+	return err.Error()
+}
+
+// localizeWrapped replaces the message of each of the wrapped errors
+// within msg, in order, with its localized message.
+func localizeWrapped(msg string, wrapped []error, locale string, l Localizer) string {
+	sb := &strings.Builder{}
+	for _, err := range wrapped {
+		if err == nil {
+			continue
+		}
+		inner := err.Error()
+		i := strings.Index(msg, inner)
+		if i == -1 {
+			continue
+		}
+		sb.WriteString(msg[:i])
+		sb.WriteString(Localize(err, locale, l))
+		msg = msg[i+len(inner):]
+	}
+	sb.WriteString(msg)
+	return sb.String()
+}
+
+// Catalogue is a Localizer backed by translations held in memory,
+// create new instances with NewCatalogue or LoadCatalogue.
+//
+// Translations are keyed by locale & then by the original template.
+// Should a locale such as "en-AU" have no translation the base
+// language, "en", is tried next.
+type Catalogue struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]string
+}
+
+// NewCatalogue is the constructor for Catalogue.
+func NewCatalogue() *Catalogue {
+	return &Catalogue{messages: map[string]map[string]string{}}
+}
+
+// LoadCatalogue creates a Catalogue from the JSON or TOML files in the root
+// of fsys, each file is named after its locale, eg: fr.json or de-CH.toml,
+// and contains a flat object (or table) of templates to translations.
+//
+// For example, fr.json:
+//
+//	{"user {id} not found": "utilisateur {id} introuvable"}
+//
+// Or de-CH.toml:
+//
+//	"user {id} not found" = "Benutzer {id} nicht gefunden"
+func LoadCatalogue(fsys fs.FS) (*Catalogue, error) {
+	c := NewCatalogue()
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := path.Ext(entry.Name())
+		unmarshal, ok := catalogueFormats[ext]
+		if !ok {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		var messages map[string]string
+		if err := unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		c.Add(strings.TrimSuffix(entry.Name(), ext), messages)
+	}
+	return c, nil
+}
+
+// catalogueFormats are the decoders of the files read by LoadCatalogue.
+var catalogueFormats = map[string]func([]byte, interface{}) error{
+	".json": json.Unmarshal,
+	".toml": toml.Unmarshal,
+}
+
+// Add adds translations for locale, replacing any existing
+// translations of the same templates.
+func (c *Catalogue) Add(locale string, messages map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.messages[locale] == nil {
+		c.messages[locale] = map[string]string{}
+	}
+	for k, v := range messages {
+		c.messages[locale][k] = v
+	}
+}
+
+// Localize implements the Localizer interface.
+func (c *Catalogue) Localize(locale, template string, params Params) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for {
+		if translated, ok := c.messages[locale][template]; ok {
+			return RenderTemplate(translated, params), true
+		}
+		i := strings.LastIndexAny(locale, "-_")
+		if i == -1 {
+			return "", false
+		}
+		locale = locale[:i]
+	}
+}
@@ -0,0 +1,81 @@
+package goerr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalize(t *testing.T) {
+	c := goerr.NewCatalogue()
+	c.Add("fr", map[string]string{"user {id} not found": "utilisateur {id} introuvable"})
+
+	e := goerr.WrapWith(
+		goerr.WrapWith(goerr.New("abc"), goerr.Template("user {id} not found", goerr.Params{"id": "123"})),
+		goerr.Msg("outer"),
+	)
+	assert.Equal(t, "outer: utilisateur 123 introuvable: abc", goerr.Localize(e, "fr", c))
+	assert.Equal(t, "outer: utilisateur 123 introuvable: abc", goerr.Localize(e, "fr-CA", c))
+	assert.Equal(t, "outer: user 123 not found: abc", goerr.Localize(e, "de", c))
+	assert.Equal(t, "", goerr.Localize(nil, "fr", c))
+}
+
+func TestLocalizeWrapped(t *testing.T) {
+	c := goerr.NewCatalogue()
+	c.Add("fr", map[string]string{"user {id} not found": "utilisateur {id} introuvable"})
+	e := goerr.WrapWith(errors.New("abc"), goerr.Template("user {id} not found", goerr.Params{"id": 123}))
+
+	assert.Equal(t, "loading: utilisateur 123 introuvable: abc", goerr.Localize(fmt.Errorf("loading: %w", e), "fr", c))
+	assert.Equal(t, "utilisateur 123 introuvable: abc\nxyz", goerr.Localize(errors.Join(e, errors.New("xyz")), "fr", c))
+	assert.Equal(t, "utilisateur 123 introuvable: abc\nxyz", goerr.Localize(goerr.Join(e, errors.New("xyz")), "fr", c))
+
+	j, err := json.Marshal(goerr.NewStackTrace(goerr.Wrap(e, "outer")))
+	if assert.NoError(t, err) {
+		remote, err := goerr.ParseTrace(j)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "outer: utilisateur 123 introuvable: abc", goerr.Localize(remote.Error, "fr", c))
+			assert.Equal(t, "outer: user 123 not found: abc", goerr.Localize(remote.Error, "de", c))
+		}
+	}
+}
+
+func TestLoadCatalogue(t *testing.T) {
+	c, err := goerr.LoadCatalogue(fstest.MapFS{
+		"fr.json":    {Data: []byte(`{"user {id} not found": "utilisateur {id} introuvable"}`)},
+		"de-CH.json": {Data: []byte(`{"user {id} not found": "Benutzer {id} nicht gefunden", "loading": "lade"}`)},
+		"es.toml":    {Data: []byte("# Spanish\n\"loading\" = \"cargando\"\n\"user {id} not found\" = 'usuario {id} no encontrado'\n")},
+		"README.md":  {Data: []byte("ignored")},
+	})
+	if assert.NoError(t, err) {
+		params := goerr.Params{"id": "123"}
+		msg, ok := c.Localize("fr", "user {id} not found", params)
+		assert.True(t, ok)
+		assert.Equal(t, "utilisateur 123 introuvable", msg)
+		msg, _ = c.Localize("de-CH", "user {id} not found", params)
+		assert.Equal(t, "Benutzer 123 nicht gefunden", msg)
+		msg, _ = c.Localize("de-CH", "loading", nil)
+		assert.Equal(t, "lade", msg)
+		_, ok = c.Localize("de", "loading", nil)
+		assert.False(t, ok)
+		msg, _ = c.Localize("es", "loading", nil)
+		assert.Equal(t, "cargando", msg)
+		msg, _ = c.Localize("es", "user {id} not found", params)
+		assert.Equal(t, "usuario 123 no encontrado", msg)
+	}
+
+	_, err = goerr.LoadCatalogue(fstest.MapFS{"es.json": {Data: []byte(`{"a": 1}`)}})
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "es.json: "))
+	}
+
+	_, err = goerr.LoadCatalogue(fstest.MapFS{"es.toml": {Data: []byte(`[loading]`)}})
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "es.toml: "))
+	}
+}
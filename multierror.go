@@ -0,0 +1,111 @@
+package goerr
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MultiError is an error object that holds many errors,
+// create new instances with `Join()`.
+type MultiError struct {
+	errs []error
+}
+
+// Join returns an error that wraps the given errors.
+//
+// Any nil error values are discarded. Join returns nil if every value in
+// errs is nil. If only a single non-nil error is given it is returned as is.
+//
+// This is much the same as https://golang.org/pkg/errors/#Join but the
+// returned *MultiError is understood by NewStackTrace which will generate
+// a trace for each of the joined errors.
+func Join(errs ...error) error {
+	m := &MultiError{}
+	for _, err := range errs {
+		if err != nil {
+			m.errs = append(m.errs, err)
+		}
+	}
+	switch len(m.errs) {
+	case 0:
+		return nil
+	case 1:
+		return m.errs[0]
+	}
+	return m
+}
+
+// Error implements the stdlib error interface.
+//
+// The messages of each error are joined with newlines.
+func (m *MultiError) Error() string {
+	msgs := make([]string, len(m.errs))
+	for i, err := range m.errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Format implements the fmt.Formatter interface.
+//
+// The %v, %s & %q verbs output the error message,
+// %+v outputs the complete stack trace as per NewStackTrace
+// and %#v outputs a Go-syntax representation of the joined errors.
+func (m *MultiError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, NewStackTrace(m).String())
+			return
+		}
+		if s.Flag('#') {
+			io.WriteString(s, m.GoString())
+			return
+		}
+		io.WriteString(s, m.Error())
+	case 's':
+		io.WriteString(s, m.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", m.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(*goerr.MultiError=%s)", verb, m.Error())
+	}
+}
+
+// GoString implements the fmt.GoStringer interface.
+// Sensitive data is redacted, see RedactKeys & RedactMessages.
+func (m *MultiError) GoString() string {
+	errs := make([]string, len(m.errs))
+	for i, err := range m.errs {
+		errs[i] = goString(err)
+	}
+	return fmt.Sprintf("&goerr.MultiError{errs:[]error{%s}}", strings.Join(errs, ", "))
+}
+
+// Unwrap returns the joined errors, this is understood
+// by the stdlib errors.Is & errors.As functions.
+func (m *MultiError) Unwrap() []error {
+	return m.errs
+}
+
+// Errors returns the joined errors.
+func (m *MultiError) Errors() []error {
+	return m.errs
+}
+
+// Causes will return the cause of each branch of the error tree.
+//
+// Where Cause stops at the first error that wraps many errors (such as a
+// *MultiError), Causes will continue to unwrap each of the joined errors.
+func Causes(err error) []error {
+	cause := Cause(err)
+	if multi, ok := cause.(interface{ Unwrap() []error }); ok {
+		causes := []error{}
+		for _, e := range multi.Unwrap() {
+			causes = append(causes, Causes(e)...)
+		}
+		return causes
+	}
+	return []error{cause}
+}
@@ -0,0 +1,84 @@
+package goerr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoin(t *testing.T) {
+	e1 := fmt.Errorf("abc")
+	e2 := goerr.New("xyz")
+	err := goerr.Join(e1, nil, e2)
+	assert.Equal(t, "abc\nxyz", err.Error())
+	assert.Equal(t, true, goerr.Is(err, e1))
+	assert.Equal(t, true, goerr.Is(err, e2))
+	var target *goerr.Error
+	assert.Equal(t, true, goerr.As(err, &target))
+	assert.Equal(t, e2, target)
+}
+
+func TestJoinNil(t *testing.T) {
+	assert.Nil(t, goerr.Join(nil, nil))
+}
+
+func TestJoinSingle(t *testing.T) {
+	e1 := fmt.Errorf("abc")
+	assert.Equal(t, e1, goerr.Join(nil, e1))
+}
+
+func TestCauses(t *testing.T) {
+	e1 := errors.New("abc")
+	e2 := errors.New("xyz")
+	err := goerr.Wrap(goerr.Join(goerr.Wrap(e1), fmt.Errorf("%w", e2)))
+	assert.Equal(t, []error{e1, e2}, goerr.Causes(err))
+	var multi *goerr.MultiError
+	if assert.Equal(t, true, goerr.As(goerr.Cause(err), &multi)) {
+		assert.Equal(t, 2, len(multi.Errors()))
+	}
+}
+
+func TestMultiErrorFormat(t *testing.T) {
+	err := goerr.Join(goerr.Wrap(errors.New("abc")), errors.New("xyz"))
+	assert.Equal(t, "abc\nxyz", fmt.Sprintf("%v", err))
+	assert.Equal(t, "abc\nxyz", fmt.Sprintf("%s", err))
+	assert.Equal(t, `"abc\nxyz"`, fmt.Sprintf("%q", err))
+	assert.Equal(t, goerr.NewStackTrace(err).String(), fmt.Sprintf("%+v", err))
+	assert.Contains(t, fmt.Sprintf("%+v", err), "TestMultiErrorFormat")
+	assert.Contains(t, fmt.Sprintf("%#v", err), `&goerr.MultiError{errs:[]error{&goerr.Error{message:"", frame:&goerr.StackFrame{`)
+	assert.Contains(t, fmt.Sprintf("%#v", err), `&errors.errorString{s:"xyz"}}}`)
+}
+
+func TestMultiErrorStackTrace(t *testing.T) {
+	err := goerr.Wrap(goerr.Join(goerr.Wrap(errors.New("abc")), errors.New("xyz")))
+	st := goerr.NewStackTrace(err)
+	assert.Equal(t, 1, len(st.Stack))
+	if assert.Equal(t, 2, len(st.Branches)) {
+		assert.Equal(t, "abc", st.Branches[0].ErrorMsg)
+		assert.Equal(t, 1, len(st.Branches[0].Stack))
+		assert.Equal(t, "xyz", st.Branches[1].ErrorMsg)
+		assert.Nil(t, st.Branches[1].Stack)
+	}
+	assert.Contains(t, st.String(), "--- error 2 of 2 ---\n\nxyz\n\n")
+
+	j, jerr := json.Marshal(st)
+	if assert.NoError(t, jerr) {
+		var out map[string]interface{}
+		if assert.NoError(t, json.Unmarshal(j, &out)) {
+			assert.Equal(t, 2, len(out["errors"].([]interface{})))
+		}
+	}
+}
+
+func TestMultiErrorStackTraceUnwrapped(t *testing.T) {
+	err := goerr.Join(goerr.Wrap(errors.New("abc")), errors.New("xyz"))
+	st := goerr.NewStackTrace(err)
+	assert.Nil(t, st.Stack)
+	if assert.Equal(t, 2, len(st.Branches)) {
+		assert.Equal(t, 1, len(st.Branches[0].Stack))
+	}
+}
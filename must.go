@@ -0,0 +1,29 @@
+package goerr
+
+// Must returns v if err is nil otherwise it panics
+// with the traced error, the same as Check.
+//
+// This allows the check/handle style to be used with expressions:
+//
+//	r := Must(os.Open(src))
+func Must[T any](v T, err error, messages ...string) T {
+	check(1, err, messages)
+	return v
+}
+
+// Must2 is the same as Must but for functions that return two values.
+func Must2[A, B any](a A, b B, err error, messages ...string) (A, B) {
+	check(1, err, messages)
+	return a, b
+}
+
+// Try calls fn, returning any error raised by Check, Must or Must2 within it.
+// Like HandleCheck any other panic is re-panicked with its original value.
+//
+// It converts the check/handle style back into an ordinary error:
+//
+//	n, err := Try(func() int { return Must(strconv.Atoi(s)) })
+func Try[T any](fn func() T) (v T, err error) {
+	defer HandleCheck(func(e error) { err = e })
+	return fn(), nil
+}
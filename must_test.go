@@ -0,0 +1,76 @@
+package goerr_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func atoi(s string) (n int, err error) {
+	defer goerr.Handle(func(e error) { err = e })
+	return goerr.Must(strconv.Atoi(s)), nil
+}
+
+func TestMust(t *testing.T) {
+	n, err := atoi("12")
+	assert.NoError(t, err)
+	assert.Equal(t, 12, n)
+
+	_, err = atoi("abc")
+	var e *goerr.Error
+	if assert.True(t, goerr.As(err, &e)) {
+		assert.Equal(t, goerr.CheckPanic, e.Panic())
+		assert.Equal(t, "TestMust", e.Frame().Name)
+		assert.Equal(t, "atoi", e.PanicFrame().Name)
+		line, _ := e.PanicFrame().SourceLine()
+		assert.Equal(t, "return goerr.Must(strconv.Atoi(s)), nil", line)
+	}
+}
+
+func pair(fail bool) (string, int, error) {
+	if fail {
+		return "", 0, goerr.New("abc")
+	}
+	return "a", 1, nil
+}
+
+func TestMust2(t *testing.T) {
+	s, n, err := func() (s string, n int, err error) {
+		defer goerr.Handle(func(e error) { err = e })
+		s, n = goerr.Must2(pair(false))
+		return
+	}()
+	assert.NoError(t, err)
+	assert.Equal(t, "a", s)
+	assert.Equal(t, 1, n)
+
+	_, _, err = func() (s string, n int, err error) {
+		defer goerr.Handle(func(e error) { err = e })
+		a, b, e := pair(true)
+		s, n = goerr.Must2(a, b, e, "pairing")
+		return
+	}()
+	assert.EqualError(t, err, "pairing: abc")
+}
+
+func TestTry(t *testing.T) {
+	n, err := goerr.Try(func() int { return goerr.Must(strconv.Atoi("12")) })
+	assert.NoError(t, err)
+	assert.Equal(t, 12, n)
+
+	_, err = goerr.Try(func() int {
+		n, err := strconv.Atoi("abc")
+		return goerr.Must(n, err, "parsing")
+	})
+	assert.Contains(t, err.Error(), "parsing: strconv.Atoi")
+	var e *goerr.Error
+	if assert.True(t, goerr.As(err, &e)) {
+		assert.Equal(t, "TestTry", e.Frame().Name)
+	}
+
+	assert.PanicsWithValue(t, "boom", func() {
+		goerr.Try(func() int { panic("boom") })
+	})
+}
@@ -0,0 +1,38 @@
+package goerr
+
+import (
+	"strings"
+)
+
+// Option configures an Error as it is created by TraceWith & WrapWith.
+type Option func(*Error)
+
+// Msg sets the human friendly messages that will be prefixed to the error
+// text, it is the Option equivalent of the variadic messages accepted by
+// Trace & Wrap.
+func Msg(messages ...string) Option {
+	return func(e *Error) {
+		e.message = strings.Join(messages, ": ")
+	}
+}
+
+// FullStack requests that the complete call stack be captured for this
+// error, regardless of the package wide SetFullStack setting.
+//
+// Only the deepest traced error in a chain captures the stack, tracing an
+// error that already has a full stack simply records another wrap point.
+func FullStack() Option {
+	return func(e *Error) {
+		e.fullStack = true
+	}
+}
+
+// With attaches a key/value pair of structured context to the error.
+//
+// The context of each error in a chain is merged into StackTrace.ErrorCtx
+// and is also attributed to the individual frame of the error that set it.
+func With(key string, value interface{}) Option {
+	return func(e *Error) {
+		e.fields = append(e.fields, field{key, value})
+	}
+}
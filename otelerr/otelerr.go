@@ -0,0 +1,137 @@
+/*
+Package otelerr records goerr errors on OpenTelemetry spans.
+
+A plain span.RecordError only records the message of an error, RecordError
+records the complete goerr trace as an exception event, using the semantic
+conventions, along with the context values of the error:
+
+	ctx, span := tracer.Start(ctx, "load-user")
+	defer span.End()
+
+	if err := loadUser(ctx, id); err != nil {
+		otelerr.RecordError(span, err)
+		return err
+	}
+*/
+package otelerr
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"math"
+	"reflect"
+
+	"github.com/brad-jones/goerr/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CtxPrefix is prefixed to the keys of the context values of an error
+// when they are recorded as attributes.
+const CtxPrefix = "goerr.ctx."
+
+// Option configures RecordError.
+type Option func(*options)
+
+type options struct {
+	stacktrace bool
+}
+
+// OmitStacktrace stops the rendered trace being recorded
+// as the exception.stacktrace attribute.
+func OmitStacktrace() Option {
+	return func(o *options) {
+		o.stacktrace = false
+	}
+}
+
+// RecordError records err on span as an exception event and sets the
+// status of the span to Error, nothing is recorded if err is nil or the
+// span is not recording.
+//
+// The event has the exception.type, exception.message & exception.stacktrace
+// attributes, the latter being the output of goerr.StackTrace.String(). The
+// goerr.Code, fingerprint & context values of the error are also attached.
+// See goerr.TypeName for how the type of the error is determined.
+func RecordError(span trace.Span, err error, opts ...Option) {
+	if err == nil || !span.IsRecording() {
+		return
+	}
+	o := &options{stacktrace: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	st := goerr.NewStackTrace(err)
+	attrs := []attribute.KeyValue{
+		semconv.ExceptionType(goerr.TypeName(err)),
+		semconv.ExceptionMessage(st.ErrorMsg),
+		attribute.String("goerr.fingerprint", st.Fingerprint()),
+	}
+	if o.stacktrace {
+		attrs = append(attrs, semconv.ExceptionStacktrace(st.String()))
+	}
+	if st.Code != goerr.OK {
+		attrs = append(attrs, attribute.String("goerr.code", st.Code.String()))
+	}
+	attrs = append(attrs, ctxAttributes(st)...)
+
+	span.AddEvent(semconv.ExceptionEventName, trace.WithAttributes(attrs...))
+	span.SetStatus(codes.Error, st.ErrorMsg)
+}
+
+// SpanContext is a goerr.ContextExtractor that captures the trace & span id
+// of the span in the context, register it to have them captured by WrapCtx:
+//
+//	goerr.RegisterContextExtractor("otel", otelerr.SpanContext)
+func SpanContext(ctx context.Context) map[string]interface{} {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return map[string]interface{}{
+		"trace_id": sc.TraceID().String(),
+		"span_id":  sc.SpanID().String(),
+	}
+}
+
+// ctxAttributes converts the redacted & safe context values of a trace into
+// attributes, values that are not scalars are recorded as JSON strings.
+func ctxAttributes(st *goerr.StackTrace) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{}
+	for k, v := range st.SafeCtx() {
+		attrs = append(attrs, ctxAttribute(CtxPrefix+k, v))
+	}
+	return attrs
+}
+
+func ctxAttribute(key string, v interface{}) attribute.KeyValue {
+	switch v.(type) {
+	case nil, json.Marshaler, encoding.TextMarshaler:
+	default:
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.String:
+			return attribute.String(key, rv.String())
+		case reflect.Bool:
+			return attribute.Bool(key, rv.Bool())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return attribute.Int64(key, rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if rv.Uint() <= math.MaxInt64 {
+				return attribute.Int64(key, int64(rv.Uint()))
+			}
+		case reflect.Float32, reflect.Float64:
+			f := rv.Float()
+			if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+				return attribute.Int64(key, int64(f))
+			}
+			return attribute.Float64(key, f)
+		}
+	}
+	j, _ := json.Marshal(v)
+	return attribute.String(key, string(j))
+}
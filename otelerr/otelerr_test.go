@@ -0,0 +1,107 @@
+package otelerr_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/brad-jones/goerr/v2/otelerr"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func loadUser() error {
+	return goerr.WrapWith(errors.New("not found"),
+		goerr.Msg("loading user"),
+		goerr.With("user_id", 1),
+		goerr.With("ratio", 0.5),
+		goerr.With("admin", false),
+		goerr.With("roles", []string{"a", "b"}),
+		goerr.With("password", "hunter2"),
+		goerr.WithCode(goerr.NotFound),
+	)
+}
+
+func record(err error, opts ...otelerr.Option) tracetest.SpanStub {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	_, span := provider.Tracer("test").Start(context.Background(), "load-user")
+	otelerr.RecordError(span, err, opts...)
+	span.End()
+	return exporter.GetSpans()[0]
+}
+
+func TestRecordError(t *testing.T) {
+	span := record(loadUser())
+	assert.Equal(t, codes.Error, span.Status.Code)
+	assert.Equal(t, "loading user: not found", span.Status.Description)
+
+	if assert.Equal(t, 1, len(span.Events)) {
+		event := span.Events[0]
+		assert.Equal(t, "exception", event.Name)
+		attrs := map[attribute.Key]attribute.Value{}
+		for _, kv := range event.Attributes {
+			attrs[kv.Key] = kv.Value
+		}
+		assert.Equal(t, "NotFound", attrs["exception.type"].AsString())
+		assert.Equal(t, "loading user: not found", attrs["exception.message"].AsString())
+		assert.True(t, strings.HasPrefix(attrs["exception.stacktrace"].AsString(), "loading user: not found [NotFound]\n"))
+		assert.Contains(t, attrs["exception.stacktrace"].AsString(), "otelerr_test.loadUser:")
+		assert.Equal(t, "NotFound", attrs["goerr.code"].AsString())
+		assert.Equal(t, 16, len(attrs["goerr.fingerprint"].AsString()))
+		assert.Equal(t, int64(1), attrs["goerr.ctx.user_id"].AsInt64())
+		assert.Equal(t, 0.5, attrs["goerr.ctx.ratio"].AsFloat64())
+		assert.Equal(t, false, attrs["goerr.ctx.admin"].AsBool())
+		assert.Equal(t, `["a","b"]`, attrs["goerr.ctx.roles"].AsString())
+		assert.Equal(t, goerr.Redacted, attrs["goerr.ctx.password"].AsString())
+	}
+}
+
+func TestRecordErrorOmitStacktrace(t *testing.T) {
+	span := record(loadUser(), otelerr.OmitStacktrace())
+	for _, kv := range span.Events[0].Attributes {
+		assert.NotEqual(t, attribute.Key("exception.stacktrace"), kv.Key)
+	}
+}
+
+func TestRecordErrorNil(t *testing.T) {
+	span := record(nil)
+	assert.Equal(t, codes.Unset, span.Status.Code)
+	assert.Equal(t, 0, len(span.Events))
+}
+
+type countingSpan struct {
+	noop.Span
+	events int
+}
+
+func (s *countingSpan) AddEvent(name string, opts ...trace.EventOption) {
+	s.events++
+}
+
+func TestRecordErrorNotRecording(t *testing.T) {
+	span := &countingSpan{}
+	otelerr.RecordError(span, loadUser())
+	assert.Equal(t, 0, span.events)
+}
+
+func TestSpanContext(t *testing.T) {
+	goerr.RegisterContextExtractor("otel", otelerr.SpanContext)
+	defer goerr.UnregisterContextExtractor("otel")
+
+	provider := sdktrace.NewTracerProvider()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "load-user")
+	defer span.End()
+
+	st := goerr.NewStackTrace(goerr.WrapCtx(ctx, errors.New("boom")))
+	assert.Equal(t, span.SpanContext().TraceID().String(), st.ErrorCtx["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), st.ErrorCtx["span_id"])
+	assert.Nil(t, otelerr.SpanContext(context.Background()))
+}
@@ -0,0 +1,194 @@
+package goerr
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// PanicKind describes how an error came to be recovered from a panic.
+type PanicKind int
+
+const (
+	// NotPanicked is the PanicKind of an error that was never panicked.
+	NotPanicked PanicKind = iota
+
+	// CheckPanic is the PanicKind of an error raised by Check, Must or Must2.
+	CheckPanic
+
+	// ForeignPanic is the PanicKind of any other panic, such as
+	// panic("x") or a runtime.Error like a nil pointer dereference.
+	ForeignPanic
+)
+
+var panicKindNames = [...]string{"", "check", "foreign"}
+
+// String returns the name of the PanicKind, NotPanicked is an empty string.
+func (k PanicKind) String() string {
+	if k < 0 || int(k) >= len(panicKindNames) {
+		return fmt.Sprintf("PanicKind(%d)", int(k))
+	}
+	return panicKindNames[k]
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (k PanicKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (k *PanicKind) UnmarshalText(text []byte) error {
+	for i, name := range panicKindNames {
+		if name == string(text) {
+			*k = PanicKind(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown panic kind %q", text)
+}
+
+// Recovered converts a value returned by recover() into an *Error.
+//
+// It must be called by the deferred function that recovered the value,
+// while the panicking call stack is still intact, as the error is traced
+// to the frame that actually panicked rather than a fixed call depth.
+//
+// Errors raised by Check, Must or Must2 are instead traced to the caller
+// of the function that called them, the same as Handle has always done.
+// nil is returned if value is nil.
+func Recovered(value interface{}) *Error {
+	if value == nil {
+		return nil
+	}
+	return recovered(value, panicStack(1), atomic.LoadInt32(&fullStack) == 1)
+}
+
+func recovered(value interface{}, pcs []uintptr, withStack bool) *Error {
+	if e, ok := value.(*Error); ok && e.checked {
+		traced := &Error{innerErr: e, panic: CheckPanic, panicPC: e.caller}
+
+		if frame, ok := checkCaller(pcs); ok {
+			traced.caller = frame.PC
+		}
+		return traced
+	}
+
+	err, ok := value.(*Error)
+	if !ok {
+		e, ok := value.(error)
+		if !ok {
+			e = fmt.Errorf("%v", value)
+		}
+		err = New(e)
+	}
+
+	traced := &Error{innerErr: err, panic: ForeignPanic}
+	if frame, ok := nthFrame(pcs, 1); ok {
+		traced.caller = frame.PC
+		traced.panicPC = frame.PC
+		if withStack && !err.hasStack() {
+			traced.stack = pcs[1:]
+		}
+	}
+	return traced
+}
+
+// panicStack returns the call stack of a panicking goroutine starting with
+// the last runtime frame before the frame that panicked, such as
+// runtime.gopanic or runtime.sigpanic. It must be called from a deferred
+// function while panicking otherwise nil is returned.
+func panicStack(skip int) []uintptr {
+	pcs := callers(skip + 1)
+
+	// Find runtime.gopanic, any further runtime frames after it, such as
+	// runtime.sigpanic or runtime.panicmem, lead to the frame that panicked.
+	i := 0
+	for ; i < len(pcs); i++ {
+		if fn := runtime.FuncForPC(pcs[i] - 1); fn != nil && fn.Name() == "runtime.gopanic" {
+			break
+		}
+	}
+	if i == len(pcs) {
+		return nil
+	}
+	for i++; i < len(pcs); i++ {
+		fn := runtime.FuncForPC(pcs[i] - 1)
+		if fn == nil || !strings.HasPrefix(fn.Name(), "runtime.") {
+			break
+		}
+	}
+	if i == len(pcs) {
+		return nil
+	}
+	return pcs[i-1:]
+}
+
+// nthFrame resolves the nth frame of pcs, counting inlined calls.
+//
+// Frames are always resolved with the preceding frame included such
+// that the program counter of a faulting instruction (ie: sigpanic) is
+// correct, hence the frame at index 0 itself can not be resolved.
+func nthFrame(pcs []uintptr, n int) (runtime.Frame, bool) {
+	if n < 1 || len(pcs) == 0 {
+		return runtime.Frame{}, false
+	}
+	frames := runtime.CallersFrames(pcs)
+	for i := 0; ; i++ {
+		frame, more := frames.Next()
+		if i == n {
+			return frame, true
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+// checkCaller resolves the caller of the function that called Check, or
+// Must etc, from the stack of a Check panic. Frames of this package, such
+// as Try, are skipped so the caller is always a frame of user code.
+func checkCaller(pcs []uintptr) (runtime.Frame, bool) {
+	if len(pcs) == 0 {
+		return runtime.Frame{}, false
+	}
+	pkg := pkgPrefix()
+	internal := func(f runtime.Frame) bool {
+		return strings.HasPrefix(f.Function, "runtime.") || strings.HasPrefix(f.Function, pkg)
+	}
+	frames := runtime.CallersFrames(pcs)
+	found := false
+	for {
+		frame, more := frames.Next()
+		if !internal(frame) {
+			if found {
+				return frame, true
+			}
+			found = true
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+// pkgPrefix returns the fully qualified name prefix of functions in this package.
+func pkgPrefix() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	return name[:strings.LastIndex(name, ".")+1]
+}
+
+// panicOf returns the PanicKind of the first error
+// in err's chain that was recovered from a panic.
+func panicOf(err error) PanicKind {
+	kind := NotPanicked
+	walk(err, func(e error) bool {
+		if p, ok := e.(interface{ Panic() PanicKind }); ok && p.Panic() != NotPanicked {
+			kind = p.Panic()
+			return false
+		}
+		return true
+	})
+	return kind
+}
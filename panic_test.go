@@ -0,0 +1,58 @@
+package goerr_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPanicKindText(t *testing.T) {
+	for _, k := range []goerr.PanicKind{goerr.NotPanicked, goerr.CheckPanic, goerr.ForeignPanic} {
+		text, err := k.MarshalText()
+		assert.NoError(t, err)
+		var parsed goerr.PanicKind
+		assert.NoError(t, parsed.UnmarshalText(text))
+		assert.Equal(t, k, parsed)
+	}
+	assert.Error(t, new(goerr.PanicKind).UnmarshalText([]byte("nope")))
+}
+
+func TestRecovered(t *testing.T) {
+	assert.Nil(t, goerr.Recovered(nil))
+
+	var e *goerr.Error
+	func() {
+		defer func() { e = goerr.Recovered(recover()) }()
+		var m map[string]int
+		m["a"] = 1
+	}()
+	if assert.NotNil(t, e) {
+		assert.Equal(t, goerr.ForeignPanic, e.Panic())
+		assert.Contains(t, e.Frame().Name, "TestRecovered")
+		line, _ := e.Frame().SourceLine()
+		assert.Equal(t, `m["a"] = 1`, line)
+	}
+}
+
+func TestStackTracePanic(t *testing.T) {
+	err := goerr.Wrap(panics(), "outer")
+	st := goerr.NewStackTrace(err)
+	assert.Equal(t, goerr.ForeignPanic, st.Panic)
+	assert.Contains(t, st.String(), "outer: boom (recovered from panic)\n")
+
+	j, e := json.Marshal(st)
+	if assert.NoError(t, e) {
+		assert.Contains(t, string(j), `"panic":"foreign"`)
+		parsed, e := goerr.ParseTrace(j)
+		if assert.NoError(t, e) {
+			assert.Equal(t, goerr.ForeignPanic, parsed.Panic)
+			assert.Equal(t, goerr.ForeignPanic, goerr.NewStackTrace(parsed.Error).Panic)
+		}
+	}
+
+	st = goerr.NewStackTrace(checkPanics())
+	assert.Equal(t, goerr.CheckPanic, st.Panic)
+	assert.NotContains(t, st.String(), "recovered from panic")
+}
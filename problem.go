@@ -0,0 +1,218 @@
+package goerr
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemDetails is an RFC 7807 problem details view of an error,
+// create new instances with NewProblemDetails or ParseProblem.
+//
+// see https://www.rfc-editor.org/rfc/rfc7807
+type ProblemDetails struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+}
+
+// ProblemOption configures NewProblemDetails.
+type ProblemOption func(*problemOptions)
+
+type problemOptions struct {
+	typeURI      string
+	instance     string
+	redactFrames bool
+	redactCtx    bool
+	redactDetail bool
+}
+
+// ProblemType sets the type URI of the problem, defaults to "about:blank".
+func ProblemType(uri string) ProblemOption {
+	return func(o *problemOptions) {
+		o.typeURI = uri
+	}
+}
+
+// ProblemInstance sets the URI that identifies this occurrence of the problem.
+func ProblemInstance(uri string) ProblemOption {
+	return func(o *problemOptions) {
+		o.instance = uri
+	}
+}
+
+// RedactFrames stops the stack frames of the error being included.
+func RedactFrames() ProblemOption {
+	return func(o *problemOptions) {
+		o.redactFrames = true
+	}
+}
+
+// RedactCtx stops the context values of the error being included.
+func RedactCtx() ProblemOption {
+	return func(o *problemOptions) {
+		o.redactCtx = true
+	}
+}
+
+// RedactDetail stops the error message being included as the detail.
+func RedactDetail() ProblemOption {
+	return func(o *problemOptions) {
+		o.redactDetail = true
+	}
+}
+
+// NewProblemDetails is the constructor for ProblemDetails.
+//
+// The status is derived from the Code of the error, the detail is the error
+// message and the context values (ErrorCtx), Code & stack frames of the
+// error are included as the extension members "ctx", "code" & "stack".
+func NewProblemDetails(err error, opts ...ProblemOption) *ProblemDetails {
+	o := &problemOptions{typeURI: "about:blank"}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	st := NewStackTrace(err)
+	status := CodeOf(err).HTTPStatus()
+	p := &ProblemDetails{
+		Type:       o.typeURI,
+		Title:      http.StatusText(status),
+		Status:     status,
+		Instance:   o.instance,
+		Extensions: map[string]interface{}{},
+	}
+
+	if !o.redactDetail {
+		p.Detail = st.ErrorMsg
+	}
+
+	if st.Code != OK {
+		p.Extensions["code"] = st.Code
+	}
+
+	if !o.redactCtx && st.ErrorCtx != nil {
+		p.Extensions["ctx"] = st.SafeCtx()
+	}
+
+	if !o.redactFrames && st.Stack != nil {
+		p.Extensions["stack"] = st.Stack
+	}
+
+	return p
+}
+
+// ParseProblem decodes an RFC 7807 problem+json body, such as one from a
+// downstream service, back into an error.
+//
+// The returned *Error is traced at the caller of ParseProblem and wraps a
+// *RemoteError that preserves the detail (or title) as the message, the
+// Code (from the "code" extension member or the status), the "ctx" extension
+// member as context values and the "stack" extension member as its frames.
+// Extension members that can not be decoded are ignored.
+func ParseProblem(data []byte) (*Error, error) {
+	p := &ProblemDetails{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	remote := &RemoteError{
+		message: p.Detail,
+		code:    CodeFromHTTPStatus(p.Status),
+	}
+	if remote.message == "" {
+		remote.message = p.Title
+	}
+
+	// Extension members are not part of the standard so other services may
+	// use the same names differently, any that do not decode are ignored.
+	var code Code
+	if raw, ok := members["code"]; ok && json.Unmarshal(raw, &code) == nil {
+		remote.code = code
+	}
+	var ctx map[string]interface{}
+	if raw, ok := members["ctx"]; ok && json.Unmarshal(raw, &ctx) == nil {
+		remote.ctx = ctx
+	}
+	var stack []*StackFrame
+	if raw, ok := members["stack"]; ok && json.Unmarshal(raw, &stack) == nil {
+		remote.frames = stack
+	}
+
+	return TraceWith(1, remote), nil
+}
+
+// Respond writes the problem details to w as an application/problem+json
+// response with the status of the problem.
+func (p *ProblemDetails) Respond(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	return json.NewEncoder(w).Encode(p)
+}
+
+// MarshalJSON implements the Marshaler interface
+// see https://golang.org/pkg/encoding/json/#Marshaler
+//
+// Extension members are output along side the standard members.
+func (p *ProblemDetails) MarshalJSON() ([]byte, error) {
+	data := map[string]interface{}{}
+	for k, v := range p.Extensions {
+		data[k] = v
+	}
+	members := map[string]interface{}{
+		"type":     p.Type,
+		"title":    p.Title,
+		"status":   p.Status,
+		"detail":   p.Detail,
+		"instance": p.Instance,
+	}
+	for k, v := range members {
+		if v != "" && v != 0 {
+			data[k] = v
+		}
+	}
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON implements the Unmarshaler interface
+// see https://golang.org/pkg/encoding/json/#Unmarshaler
+//
+// Any members other than the standard members are set as Extensions.
+func (p *ProblemDetails) UnmarshalJSON(b []byte) error {
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*p = ProblemDetails{}
+	standard := map[string]interface{}{
+		"type":     &p.Type,
+		"title":    &p.Title,
+		"status":   &p.Status,
+		"detail":   &p.Detail,
+		"instance": &p.Instance,
+	}
+	for k, raw := range data {
+		if target, ok := standard[k]; ok {
+			if err := json.Unmarshal(raw, target); err != nil {
+				return err
+			}
+			continue
+		}
+		var v interface{}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return err
+		}
+		if p.Extensions == nil {
+			p.Extensions = map[string]interface{}{}
+		}
+		p.Extensions[k] = v
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package goerr_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProblemDetails(t *testing.T) {
+	err := goerr.WrapWith(errCodeNotFound, goerr.Msg("loading user"), goerr.With("user_id", 123))
+	p := goerr.NewProblemDetails(err, goerr.ProblemInstance("/users/123"))
+	assert.Equal(t, "about:blank", p.Type)
+	assert.Equal(t, "Not Found", p.Title)
+	assert.Equal(t, 404, p.Status)
+	assert.Equal(t, "loading user: not found", p.Detail)
+	assert.Equal(t, "/users/123", p.Instance)
+	assert.Equal(t, goerr.NotFound, p.Extensions["code"])
+	assert.Equal(t, map[string]interface{}{"user_id": 123}, p.Extensions["ctx"])
+	assert.Equal(t, 1, len(p.Extensions["stack"].([]*goerr.StackFrame)))
+
+	p = goerr.NewProblemDetails(err, goerr.ProblemType("https://example.com/not-found"), goerr.RedactCtx(), goerr.RedactFrames(), goerr.RedactDetail())
+	assert.Equal(t, "https://example.com/not-found", p.Type)
+	assert.Equal(t, "", p.Detail)
+	assert.Nil(t, p.Extensions["ctx"])
+	assert.Nil(t, p.Extensions["stack"])
+}
+
+func TestProblemDetailsJSON(t *testing.T) {
+	p := &goerr.ProblemDetails{
+		Type:       "about:blank",
+		Title:      "Not Found",
+		Status:     404,
+		Extensions: map[string]interface{}{"foo": "bar"},
+	}
+	j, err := json.Marshal(p)
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404,"foo":"bar"}`, string(j))
+		decoded := &goerr.ProblemDetails{}
+		if assert.NoError(t, json.Unmarshal(j, decoded)) {
+			assert.Equal(t, p, decoded)
+		}
+	}
+}
+
+func TestProblemDetailsRespond(t *testing.T) {
+	rec := httptest.NewRecorder()
+	assert.NoError(t, goerr.NewProblemDetails(errCodeNotFound).Respond(rec))
+	assert.Equal(t, 404, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+}
+
+func TestParseProblem(t *testing.T) {
+	original := goerr.WrapWith(errCodeNotFound, goerr.With("user_id", 123))
+	j, err := json.Marshal(goerr.NewProblemDetails(original))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	parsed, err := goerr.ParseProblem(j)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "not found", parsed.Error())
+		assert.Equal(t, goerr.NotFound, goerr.CodeOf(parsed))
+		assert.Equal(t, "TestParseProblem", parsed.Frame().Name)
+		st := goerr.NewStackTrace(parsed)
+		assert.Equal(t, map[string]interface{}{"user_id": float64(123)}, st.ErrorCtx)
+		if assert.Equal(t, 2, len(st.Stack)) {
+			assert.Equal(t, original.Frame().LineNumber, st.Stack[0].LineNumber)
+		}
+	}
+
+	parsed, err = goerr.ParseProblem([]byte(`{"title": "Service Unavailable", "status": 503}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Service Unavailable", parsed.Error())
+		assert.Equal(t, goerr.Unavailable, goerr.CodeOf(parsed))
+	}
+
+	parsed, err = goerr.ParseProblem([]byte(`{"status": 422, "code": "E42", "ctx": [1], "stack": "at main.go:12"}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, goerr.CodeFromHTTPStatus(422), goerr.CodeOf(parsed))
+		st := goerr.NewStackTrace(parsed)
+		assert.Nil(t, st.ErrorCtx)
+		assert.Equal(t, 1, len(st.Stack))
+	}
+
+	parsed, err = goerr.ParseProblem([]byte(`{"status": 404, "code": 42}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, goerr.NotFound, goerr.CodeOf(parsed))
+	}
+
+	_, err = goerr.ParseProblem([]byte(`[]`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,320 @@
+package goerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Redacted is the value that replaces any redacted data.
+const Redacted = "[REDACTED]"
+
+// Redactor is a hook that can redact sensitive data from an error before it
+// leaves the process, it is applied once by NewStackTrace when the trace is
+// built, after the built in redaction. see SetRedactor
+type Redactor interface {
+	// RedactValue returns the value to output for the context value
+	// with the given key, return the Redacted constant to redact it.
+	RedactValue(key string, value interface{}) interface{}
+
+	// RedactMessage returns the error message to output.
+	RedactMessage(msg string) string
+}
+
+var defaultRedactKeys = []string{
+	"*password*", "*passwd*", "*secret*", "*token*",
+	"*api_key*", "*apikey*", "authorization", "cookie",
+}
+
+var redaction = &redactor{
+	keys: append([]string{}, defaultRedactKeys...),
+}
+
+type redactor struct {
+	mu       sync.RWMutex
+	keys     []string
+	messages []*regexp.Regexp
+	hook     Redactor
+}
+
+// RedactKeys adds glob patterns (as per path.Match) of context keys whose
+// values will be redacted, keys are matched case insensitively at any depth.
+//
+// By default keys resembling passwords, secrets, tokens, api keys,
+// authorization headers & cookies are redacted. see ClearRedactKeys
+// & ResetRedaction
+func RedactKeys(patterns ...string) {
+	redaction.mu.Lock()
+	defer redaction.mu.Unlock()
+	for _, p := range patterns {
+		redaction.keys = append(redaction.keys, strings.ToLower(p))
+	}
+}
+
+// ClearRedactKeys removes all registered key patterns, including the defaults.
+func ClearRedactKeys() {
+	redaction.mu.Lock()
+	defer redaction.mu.Unlock()
+	redaction.keys = nil
+}
+
+// ResetRedaction restores the default redaction configuration, removing any
+// added key patterns, message patterns & Redactor hook.
+func ResetRedaction() {
+	redaction.mu.Lock()
+	defer redaction.mu.Unlock()
+	redaction.keys = append([]string{}, defaultRedactKeys...)
+	redaction.messages = nil
+	redaction.hook = nil
+}
+
+// RedactMessages adds regular expressions that will be
+// redacted from error messages, such as email addresses.
+func RedactMessages(patterns ...*regexp.Regexp) {
+	redaction.mu.Lock()
+	defer redaction.mu.Unlock()
+	redaction.messages = append(redaction.messages, patterns...)
+}
+
+// SetRedactor sets a Redactor hook that is applied after the
+// built in redaction, pass nil to remove a previously set hook.
+func SetRedactor(r Redactor) {
+	redaction.mu.Lock()
+	defer redaction.mu.Unlock()
+	redaction.hook = r
+}
+
+func (r *redactor) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, p := range r.keys {
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// redactMessage returns msg with any sensitive data matching
+// the built in redaction redacted, it is safe to apply repeatedly.
+func redactMessage(msg string) string {
+	redaction.mu.RLock()
+	defer redaction.mu.RUnlock()
+	for _, re := range redaction.messages {
+		msg = re.ReplaceAllString(msg, Redacted)
+	}
+	return msg
+}
+
+// redactCtx returns a copy of ctx with any sensitive values matching
+// the built in redaction redacted, it is safe to apply repeatedly.
+func redactCtx(ctx map[string]interface{}) map[string]interface{} {
+	if ctx == nil {
+		return nil
+	}
+	redaction.mu.RLock()
+	defer redaction.mu.RUnlock()
+	return redaction.redactMap(ctx, map[uintptr]bool{}, nil)
+}
+
+// redactTraceMessage is redactMessage followed by the Redactor hook,
+// it must only be applied once as a trace is built.
+func redactTraceMessage(msg string) string {
+	msg = redactMessage(msg)
+	redaction.mu.RLock()
+	defer redaction.mu.RUnlock()
+	if redaction.hook != nil {
+		msg = redaction.hook.RedactMessage(msg)
+	}
+	return msg
+}
+
+// redactTraceCtx is redactCtx followed by the Redactor hook,
+// it must only be applied once as a trace is built.
+func redactTraceCtx(ctx map[string]interface{}) map[string]interface{} {
+	if ctx == nil {
+		return nil
+	}
+	redaction.mu.RLock()
+	defer redaction.mu.RUnlock()
+	return redaction.redactMap(ctx, map[uintptr]bool{}, redaction.hook)
+}
+
+// redactMap copies ctx redacting any sensitive values, seen guards against
+// cyclic maps which are left as is to be dealt with by safeCtx.
+func (r *redactor) redactMap(ctx map[string]interface{}, seen map[uintptr]bool, hook Redactor) map[string]interface{} {
+	ptr := reflect.ValueOf(ctx).Pointer()
+	seen[ptr] = true
+	defer delete(seen, ptr)
+
+	out := make(map[string]interface{}, len(ctx))
+	for k, v := range ctx {
+		if r.matchKey(k) {
+			out[k] = Redacted
+			continue
+		}
+		if nested, ok := v.(map[string]interface{}); ok && !seen[reflect.ValueOf(nested).Pointer()] {
+			v = r.redactMap(nested, seen, hook)
+		} else {
+			v = redactTaggedValue(v)
+		}
+		if hook != nil {
+			v = hook.RedactValue(k, v)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// redactTaggedValue returns the JSON representation of v, with any struct
+// fields tagged `goerr:"redact"` redacted. v is returned as is if it is not
+// a struct, or a slice, array or map of structs, with any tagged fields.
+func redactTaggedValue(v interface{}) interface{} {
+	if v == nil || !hasRedactTag(reflect.TypeOf(v)) {
+		return v
+	}
+	j, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(j, &out); err != nil || out == nil {
+		return v
+	}
+	redactTagged(reflect.ValueOf(v), out)
+	return out
+}
+
+// redactTagTypes caches the result of hasRedactTag for each type.
+var redactTagTypes sync.Map
+
+// hasRedactTag reports if t is a struct, or a pointer, slice, array or map
+// of structs, with any fields (at any depth) tagged `goerr:"redact"`.
+func hasRedactTag(t reflect.Type) bool {
+	if cached, ok := redactTagTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	tagged := hasRedactTagSeen(t, map[reflect.Type]bool{})
+	redactTagTypes.Store(t, tagged)
+	return tagged
+}
+
+func hasRedactTagSeen(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return hasRedactTagSeen(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			if f.Tag.Get("goerr") == "redact" || hasRedactTagSeen(f.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redactTagged redacts the values of any struct fields of v tagged with
+// `goerr:"redact"` from j, the decoded JSON representation of v.
+func redactTagged(v reflect.Value, j interface{}) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if !hasRedactTag(v.Type()) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		items, ok := j.([]interface{})
+		if !ok || len(items) != v.Len() {
+			return
+		}
+		for i := range items {
+			redactTagged(v.Index(i), items[i])
+		}
+	case reflect.Map:
+		m, ok := j.(map[string]interface{})
+		if !ok {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if nested, ok := m[fmt.Sprint(iter.Key().Interface())]; ok {
+				redactTagged(iter.Value(), nested)
+			}
+		}
+	case reflect.Struct:
+		if m, ok := j.(map[string]interface{}); ok {
+			redactFields(v, m)
+		}
+	}
+}
+
+// redactFields redacts the values of any fields of the struct v tagged
+// with `goerr:"redact"` from m, the decoded JSON representation of v.
+func redactFields(v reflect.Value, m map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		key := f.Name
+		if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			key = tag
+		}
+
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			redactTagged(v.Field(i), m)
+			continue
+		}
+
+		if _, ok := m[key]; !ok {
+			continue
+		}
+
+		if f.Tag.Get("goerr") == "redact" {
+			m[key] = Redacted
+			continue
+		}
+
+		redactTagged(v.Field(i), m[key])
+	}
+}
+
+// marshalError returns the JSON representation of err as a map,
+// with any fields tagged `goerr:"redact"` redacted.
+func marshalError(err error) map[string]interface{} {
+	if j, jerr := json.Marshal(err); jerr == nil {
+		jS := string(j)
+		if strings.HasPrefix(jS, "{") && jS != "{}" {
+			var out map[string]interface{}
+			if err := json.Unmarshal(j, &out); err != nil {
+				return nil
+			}
+			redactTagged(reflect.ValueOf(err), out)
+			return out
+		}
+	}
+	return nil
+}
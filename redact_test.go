@@ -0,0 +1,216 @@
+package goerr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type loginError struct {
+	Username string
+	Password string `goerr:"redact"`
+	Profile  struct {
+		Email string `json:"email" goerr:"redact"`
+		Age   int    `json:"age"`
+	} `json:"profile"`
+}
+
+func (e *loginError) Error() string {
+	return "login failed"
+}
+
+func TestRedactTaggedFields(t *testing.T) {
+	cause := &loginError{Username: "brad", Password: "hunter2"}
+	cause.Profile.Email = "brad@example.com"
+	cause.Profile.Age = 42
+	st := goerr.NewStackTrace(goerr.Wrap(cause))
+	assert.Equal(t, map[string]interface{}{
+		"Username": "brad",
+		"Password": goerr.Redacted,
+		"profile":  map[string]interface{}{"email": goerr.Redacted, "age": float64(42)},
+	}, st.ErrorCtx)
+	assert.NotContains(t, st.String(), "hunter2")
+	assert.NotContains(t, st.String(), "brad@example.com")
+}
+
+type credentials struct {
+	User     string `json:"user"`
+	Password string `json:"pass" goerr:"redact"`
+}
+
+func TestRedactTaggedWith(t *testing.T) {
+	err := goerr.WrapWith(goerr.New("abc"), goerr.With("creds", &credentials{User: "brad", Password: "hunter2"}))
+	st := goerr.NewStackTrace(err)
+	expected := map[string]interface{}{"user": "brad", "pass": goerr.Redacted}
+	assert.Equal(t, expected, st.ErrorCtx["creds"])
+	assert.Equal(t, expected, st.Stack[0].Context["creds"])
+
+	j, jerr := json.Marshal(st)
+	if assert.NoError(t, jerr) {
+		assert.Contains(t, string(j), `"error-ctx":{"creds":{"pass":"[REDACTED]","user":"brad"}}`)
+	}
+	assert.Contains(t, st.String(), "\tctx: creds=map[pass:[REDACTED] user:brad]\n")
+	assert.NotContains(t, fmt.Sprintf("%#v", err), "hunter2")
+	assert.Contains(t, fmt.Sprintf("%#v", err), `"pass":"[REDACTED]"`)
+
+	manual := &goerr.StackTrace{ErrorMsg: "abc", ErrorCtx: map[string]interface{}{"creds": credentials{Password: "hunter2"}}}
+	assert.NotContains(t, manual.String(), "hunter2")
+}
+
+type vaultError struct {
+	Creds  []credentials          `json:"creds"`
+	ByName map[string]credentials `json:"by_name"`
+}
+
+func (e *vaultError) Error() string {
+	return "vault sealed"
+}
+
+func TestRedactTaggedCollections(t *testing.T) {
+	expected := []interface{}{map[string]interface{}{"user": "brad", "pass": goerr.Redacted}}
+
+	cause := &vaultError{
+		Creds:  []credentials{{User: "brad", Password: "hunter2"}},
+		ByName: map[string]credentials{"brad": {User: "brad", Password: "hunter2"}},
+	}
+	st := goerr.NewStackTrace(goerr.Wrap(cause))
+	assert.Equal(t, expected, st.ErrorCtx["creds"])
+	assert.Equal(t, map[string]interface{}{"brad": expected[0]}, st.ErrorCtx["by_name"])
+	assert.NotContains(t, st.String(), "hunter2")
+
+	err := goerr.WrapWith(goerr.New("abc"),
+		goerr.With("list", []credentials{{User: "brad", Password: "hunter2"}}),
+		goerr.With("map", map[string]*credentials{"brad": {User: "brad", Password: "hunter2"}}),
+	)
+	st = goerr.NewStackTrace(err)
+	assert.Equal(t, expected, st.ErrorCtx["list"])
+	assert.Equal(t, expected, st.Stack[0].Context["list"])
+	assert.Equal(t, map[string]interface{}{"brad": expected[0]}, st.ErrorCtx["map"])
+	assert.Equal(t, map[string]interface{}{"brad": expected[0]}, st.Stack[0].Context["map"])
+
+	j, jerr := json.Marshal(st)
+	if assert.NoError(t, jerr) {
+		assert.NotContains(t, string(j), "hunter2")
+	}
+	assert.NotContains(t, st.String(), "hunter2")
+	assert.NotContains(t, fmt.Sprintf("%#v", err), "hunter2")
+}
+
+func TestRedactGoString(t *testing.T) {
+	goerr.RedactMessages(regexp.MustCompile(`[a-z]+@example\.com`))
+	defer goerr.ResetRedaction()
+
+	cause := &loginError{Username: "brad", Password: "hunter2"}
+	cause.Profile.Email = "brad@example.com"
+	out := fmt.Sprintf("%#v", goerr.Wrap(cause, "login for brad@example.com"))
+	assert.NotContains(t, out, "hunter2")
+	assert.NotContains(t, out, "brad@example.com")
+	assert.Contains(t, out, `message:"login for [REDACTED]"`)
+	assert.Contains(t, out, `innerErr:*goerr_test.loginError(map[string]interface {}{`)
+
+	out = fmt.Sprintf("%#v", goerr.Wrap(errors.New("user brad@example.com not found")))
+	assert.Contains(t, out, `innerErr:*errors.errorString("user [REDACTED] not found")}`)
+
+	out = fmt.Sprintf("%#v", goerr.Join(cause, errors.New("abc")))
+	assert.NotContains(t, out, "hunter2")
+	assert.Contains(t, out, `&errors.errorString{s:"abc"}`)
+}
+
+func TestRedactKeys(t *testing.T) {
+	goerr.RedactKeys("*email*")
+	defer goerr.ResetRedaction()
+
+	err := goerr.WrapWith(goerr.New("abc"),
+		goerr.With("access_token", "xyz"),
+		goerr.With("user", map[string]interface{}{"Email": "brad@example.com", "id": 1}),
+		goerr.With("tenant", "acme"),
+	)
+	st := goerr.NewStackTrace(err)
+	assert.Equal(t, map[string]interface{}{
+		"access_token": goerr.Redacted,
+		"user":         map[string]interface{}{"Email": goerr.Redacted, "id": 1},
+		"tenant":       "acme",
+	}, st.ErrorCtx)
+	assert.Equal(t, goerr.Redacted, st.Stack[0].Context["access_token"])
+
+	manual := &goerr.StackTrace{ErrorMsg: "abc", ErrorCtx: map[string]interface{}{"password": "hunter2"}}
+	assert.NotContains(t, manual.String(), "hunter2")
+	j, jerr := json.Marshal(manual)
+	if assert.NoError(t, jerr) {
+		assert.NotContains(t, string(j), "hunter2")
+	}
+}
+
+func TestRedactMessages(t *testing.T) {
+	goerr.RedactMessages(regexp.MustCompile(`[a-z]+@example\.com`))
+	defer goerr.ResetRedaction()
+
+	st := goerr.NewStackTrace(goerr.New("user brad@example.com not found"))
+	assert.Equal(t, "user [REDACTED] not found", st.ErrorMsg)
+}
+
+type upperRedactor struct{}
+
+func (upperRedactor) RedactValue(key string, value interface{}) interface{} {
+	if key == "tenant" {
+		return goerr.Redacted
+	}
+	return value
+}
+
+func (upperRedactor) RedactMessage(msg string) string {
+	return strings.ToUpper(msg)
+}
+
+func TestSetRedactor(t *testing.T) {
+	goerr.SetRedactor(upperRedactor{})
+	defer goerr.ResetRedaction()
+
+	st := goerr.NewStackTrace(goerr.WrapWith(goerr.New("abc"), goerr.With("tenant", "acme")))
+	assert.Equal(t, "ABC", st.ErrorMsg)
+	assert.Equal(t, map[string]interface{}{"tenant": goerr.Redacted}, st.ErrorCtx)
+}
+
+type countingRedactor struct {
+	values, messages int
+}
+
+func (r *countingRedactor) RedactValue(key string, value interface{}) interface{} {
+	r.values++
+	return value
+}
+
+func (r *countingRedactor) RedactMessage(msg string) string {
+	r.messages++
+	return msg
+}
+
+func TestSetRedactorOnce(t *testing.T) {
+	r := &countingRedactor{}
+	goerr.SetRedactor(r)
+	defer goerr.ResetRedaction()
+
+	st := goerr.NewStackTrace(goerr.WrapWith(goerr.New("abc"), goerr.With("tenant", "acme")))
+	values, messages := r.values, r.messages
+	assert.Equal(t, 1, messages)
+
+	_ = st.String()
+	_ = st.LogValue()
+	_, _ = json.Marshal(st)
+	assert.Equal(t, values, r.values)
+	assert.Equal(t, messages, r.messages)
+}
+
+func TestClearRedactKeys(t *testing.T) {
+	goerr.ClearRedactKeys()
+	defer goerr.ResetRedaction()
+
+	st := goerr.NewStackTrace(goerr.WrapWith(goerr.New("abc"), goerr.With("password", "hunter2")))
+	assert.Equal(t, map[string]interface{}{"password": "hunter2"}, st.ErrorCtx)
+}
@@ -0,0 +1,75 @@
+package goerr
+
+import (
+	"encoding/json"
+)
+
+// RemoteError is a placeholder for an error that was deserialized from
+// a trace, it preserves the message, context and frames of the original
+// error. see ParseTrace
+type RemoteError struct {
+	message   string
+	code      Code
+	panic     PanicKind
+	templates []*MessageTemplate
+
+	fingerprint string
+	ctx         map[string]interface{}
+	frames      []*StackFrame
+	errs        []error
+}
+
+// ParseTrace reconstructs a StackTrace from the JSON output of
+// StackTrace.MarshalJSON, the Error of the trace will be a *RemoteError.
+func ParseTrace(data []byte) (*StackTrace, error) {
+	st := &StackTrace{}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// Error implements the stdlib error interface.
+func (r *RemoteError) Error() string {
+	return r.message
+}
+
+// Unwrap returns the remote errors of each branch
+// if the original error was a *MultiError.
+func (r *RemoteError) Unwrap() []error {
+	return r.errs
+}
+
+// Code returns the Code of the original error.
+func (r *RemoteError) Code() Code {
+	return r.code
+}
+
+// Panic returns how the original error was recovered from a panic.
+func (r *RemoteError) Panic() PanicKind {
+	return r.panic
+}
+
+// Templates returns the message templates of the original error chain.
+func (r *RemoteError) Templates() []*MessageTemplate {
+	return r.templates
+}
+
+// Ctx returns the context values of the original error.
+func (r *RemoteError) Ctx() map[string]interface{} {
+	return r.ctx
+}
+
+// Frames returns the stack frames of the original error.
+func (r *RemoteError) Frames() []*StackFrame {
+	return r.frames
+}
+
+// MarshalJSON implements the Marshaler interface
+// see https://golang.org/pkg/encoding/json/#Marshaler
+//
+// Only the context values are output, such that NewStackTrace
+// will restore them as the ErrorCtx of the trace.
+func (r *RemoteError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.ctx)
+}
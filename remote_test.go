@@ -0,0 +1,69 @@
+package goerr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrace(t *testing.T) {
+	err := goerr.WrapWith(&fooError{Bar: "abc"}, goerr.With("user_id", 123))
+	original := goerr.NewStackTrace(goerr.Join(err, errors.New("xyz")))
+	j, jerr := json.Marshal(original)
+	if !assert.NoError(t, jerr) {
+		return
+	}
+
+	st, perr := goerr.ParseTrace(j)
+	if assert.NoError(t, perr) {
+		var remote *goerr.RemoteError
+		if assert.True(t, goerr.As(st.Error, &remote)) {
+			assert.Equal(t, original.ErrorMsg, remote.Error())
+		}
+		assert.Equal(t, "a message\nxyz", st.ErrorMsg)
+		if assert.Equal(t, 2, len(st.Branches)) {
+			branch := st.Branches[0]
+			assert.Equal(t, map[string]interface{}{"Bar": "abc", "user_id": float64(123)}, branch.ErrorCtx)
+			if assert.Equal(t, 1, len(branch.Stack)) {
+				assert.Equal(t, "TestParseTrace", branch.Stack[0].Name)
+				assert.Equal(t, true, branch.Stack[0].Wrapped)
+				assert.Equal(t, map[string]interface{}{"user_id": float64(123)}, branch.Stack[0].Context)
+				line, err := branch.Stack[0].SourceLine()
+				if assert.NoError(t, err) {
+					assert.Equal(t, `err := goerr.WrapWith(&fooError{Bar: "abc"}, goerr.With("user_id", 123))`, line)
+				}
+			}
+		}
+
+		roundTripped, jerr := json.Marshal(st)
+		if assert.NoError(t, jerr) {
+			assert.JSONEq(t, string(j), string(roundTripped))
+		}
+	}
+}
+
+func TestParseTraceUnsupportedSchema(t *testing.T) {
+	_, err := goerr.ParseTrace([]byte(`{"schema": 99, "error-msg": "abc"}`))
+	assert.EqualError(t, err, "unsupported trace schema version 99, expected <= 1")
+}
+
+func TestRemoteErrorWrapped(t *testing.T) {
+	st, err := goerr.ParseTrace([]byte(`{
+		"error-msg": "abc",
+		"error-ctx": {"foo": "bar"},
+		"stack": [{"package": "remote", "method": "crash", "file": "/remote.go", "lineno": 12, "src": "return err"}]
+	}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	local := goerr.NewStackTrace(goerr.Wrap(st.Error, "calling remote"))
+	assert.Equal(t, "calling remote: abc", local.ErrorMsg)
+	assert.Equal(t, map[string]interface{}{"foo": "bar"}, local.ErrorCtx)
+	if assert.Equal(t, 2, len(local.Stack)) {
+		assert.Equal(t, "crash", local.Stack[0].Name)
+		assert.Equal(t, "TestRemoteErrorWrapped", local.Stack[1].Name)
+	}
+}
@@ -0,0 +1,451 @@
+package goerr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Renderer converts a StackTrace into a particular output format.
+//
+// see TextRenderer, ANSIRenderer, CompactRenderer, MarkdownRenderer & LogfmtRenderer
+type Renderer interface {
+	Render(w io.Writer, s *StackTrace) error
+}
+
+// TextRenderer renders a StackTrace as plain text, this is the
+// format used by StackTrace.String() & PrintTrace.
+//
+// For example:
+//
+//	human friendly error message
+//
+//	{
+//		"optional": "context values"
+//	}
+//
+//	the-pkg-name.theMethodName:/the/file/path/to/go/src/file:123
+//		if /the/file/path/to/go/src/file exists then this will be line 123
+type TextRenderer struct {
+	// SourceContext is the number of lines of source code to
+	// show either side of the line of each frame, defaults to 0.
+	SourceContext int
+
+	// Fingerprint shows the fingerprint of the trace under the message.
+	Fingerprint bool
+}
+
+// Render implements the Renderer interface.
+func (r TextRenderer) Render(w io.Writer, s *StackTrace) error {
+	st := redactMessage(s.ErrorMsg) + codeSuffix(s.Code) + panicSuffix(s.Panic) + "\n"
+	if r.Fingerprint {
+		st = st + "fingerprint: " + s.Fingerprint() + "\n"
+	}
+	st = st + "\n"
+
+	if s.ErrorCtx != nil {
+		ctx, problems := ctxJSON(s.ErrorCtx)
+		st = st + ctx + "\n"
+		for _, p := range problems {
+			st = st + "goerr: " + p + "\n"
+		}
+		st = st + "\n"
+	}
+
+	if s.Stack != nil {
+		highlight := s.isFull()
+		for _, f := range s.Stack {
+			if highlight {
+				if f.Wrapped {
+					st = st + "* "
+				} else {
+					st = st + "  "
+				}
+			}
+			if r.SourceContext > 0 {
+				st = st + fmt.Sprintf("%s.%s:%s:%d\n", f.Package, f.Name, f.File, f.LineNumber)
+				st = st + sourceContext(f, r.SourceContext, "\t", "")
+				if len(f.Context) > 0 {
+					st = st + fmt.Sprintf("\tctx: %s\n", formatPairs(f.Context))
+				}
+			} else {
+				st = st + f.String()
+			}
+		}
+		st = st + "\n"
+	}
+
+	if _, err := io.WriteString(w, st); err != nil {
+		return err
+	}
+
+	for i, b := range s.Branches {
+		if _, err := fmt.Fprintf(w, "--- error %d of %d ---\n\n", i+1, len(s.Branches)); err != nil {
+			return err
+		}
+		if err := r.Render(w, b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiDim    = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// ANSIRenderer renders a StackTrace in the same layout as the TextRenderer
+// but colored with ANSI escape codes, suitable for output to a terminal.
+type ANSIRenderer struct {
+	// SourceContext is the number of lines of source code to
+	// show either side of the line of each frame, defaults to 0.
+	SourceContext int
+
+	// Fingerprint shows the fingerprint of the trace under the message.
+	Fingerprint bool
+}
+
+// Render implements the Renderer interface.
+func (r ANSIRenderer) Render(w io.Writer, s *StackTrace) error {
+	st := ansiBold + ansiRed + redactMessage(s.ErrorMsg) + ansiReset
+	if s.Code != OK {
+		st = st + ansiDim + codeSuffix(s.Code) + ansiReset
+	}
+	if s.Panic == ForeignPanic {
+		st = st + ansiYellow + panicSuffix(s.Panic) + ansiReset
+	}
+	st = st + "\n"
+	if r.Fingerprint {
+		st = st + ansiDim + "fingerprint: " + s.Fingerprint() + ansiReset + "\n"
+	}
+	st = st + "\n"
+
+	if s.ErrorCtx != nil {
+		ctx, problems := ctxJSON(s.ErrorCtx)
+		st = st + ansiCyan + ctx + ansiReset + "\n"
+		for _, p := range problems {
+			st = st + ansiYellow + "goerr: " + p + ansiReset + "\n"
+		}
+		st = st + "\n"
+	}
+
+	if s.Stack != nil {
+		highlight := s.isFull()
+		for _, f := range s.Stack {
+			if highlight {
+				if f.Wrapped {
+					st = st + ansiGreen + "* " + ansiReset
+				} else {
+					st = st + "  "
+				}
+			}
+			st = st + fmt.Sprintf("%s%s.%s%s:%s%s:%d%s\n",
+				ansiYellow, f.Package, f.Name, ansiReset,
+				ansiDim, f.File, f.LineNumber, ansiReset,
+			)
+			if r.SourceContext > 0 {
+				st = st + sourceContext(f, r.SourceContext, "\t", ansiDim)
+			} else if source, err := f.SourceLine(); err == nil {
+				st = st + fmt.Sprintf("\t%s\n", source)
+			}
+			if len(f.Context) > 0 {
+				st = st + fmt.Sprintf("\t%sctx: %s%s\n", ansiCyan, formatPairs(f.Context), ansiReset)
+			}
+		}
+		st = st + "\n"
+	}
+
+	if _, err := io.WriteString(w, st); err != nil {
+		return err
+	}
+
+	for i, b := range s.Branches {
+		if _, err := fmt.Fprintf(w, "%s--- error %d of %d ---%s\n\n", ansiBold, i+1, len(s.Branches), ansiReset); err != nil {
+			return err
+		}
+		if err := r.Render(w, b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CompactRenderer renders a StackTrace on a single line.
+//
+// For example:
+//
+//	human friendly error message [key=value] at pkg.fn (/file.go:12) <- pkg.main (/main.go:3)
+type CompactRenderer struct{}
+
+// Render implements the Renderer interface.
+func (r CompactRenderer) Render(w io.Writer, s *StackTrace) error {
+	_, err := io.WriteString(w, r.render(s)+"\n")
+	return err
+}
+
+func (r CompactRenderer) render(s *StackTrace) string {
+	st := strings.ReplaceAll(redactMessage(s.ErrorMsg), "\n", "; ") + codeSuffix(s.Code) + panicSuffix(s.Panic)
+
+	if len(s.ErrorCtx) > 0 {
+		st = st + " [" + formatPairs(s.ErrorCtx) + "]"
+	}
+
+	if len(s.Stack) > 0 {
+		frames := make([]string, len(s.Stack))
+		for i, f := range s.Stack {
+			frames[i] = fmt.Sprintf("%s.%s (%s:%d)", f.Package, f.Name, f.File, f.LineNumber)
+		}
+		st = st + " at " + strings.Join(frames, " <- ")
+	}
+
+	if len(s.Branches) > 0 {
+		branches := make([]string, len(s.Branches))
+		for i, b := range s.Branches {
+			branches[i] = fmt.Sprintf("(%d) %s", i+1, r.render(b))
+		}
+		st = st + " {" + strings.Join(branches, "; ") + "}"
+	}
+
+	return st
+}
+
+// MarkdownRenderer renders a StackTrace as Markdown,
+// suitable for issue trackers, PR comments & CI annotations.
+type MarkdownRenderer struct {
+	// SourceContext is the number of lines of source code to
+	// show either side of the line of each frame, defaults to 0.
+	SourceContext int
+}
+
+// Render implements the Renderer interface.
+func (r MarkdownRenderer) Render(w io.Writer, s *StackTrace) error {
+	return r.render(w, s, 3)
+}
+
+func (r MarkdownRenderer) render(w io.Writer, s *StackTrace, level int) error {
+	heading := strings.Repeat("#", level)
+	st := fmt.Sprintf("%s %s%s%s\n\n", heading, strings.ReplaceAll(redactMessage(s.ErrorMsg), "\n", " "), codeSuffix(s.Code), panicSuffix(s.Panic))
+
+	if s.ErrorCtx != nil {
+		ctx, problems := ctxJSON(s.ErrorCtx)
+		st = st + "```json\n" + ctx + "\n```\n\n"
+		for _, p := range problems {
+			st = st + "> goerr: " + p + "\n\n"
+		}
+	}
+
+	if s.Stack != nil {
+		for i, f := range s.Stack {
+			name := fmt.Sprintf("`%s.%s`", f.Package, f.Name)
+			if f.Wrapped && s.isFull() {
+				name = "**" + name + "**"
+			}
+			st = st + fmt.Sprintf("%d. %s at `%s:%d`\n", i+1, name, f.File, f.LineNumber)
+			if r.SourceContext > 0 {
+				if source := sourceContext(f, r.SourceContext, "   ", ""); source != "" {
+					st = st + fmt.Sprintf("   ```go\n%s   ```\n", source)
+				}
+			} else if source, err := f.SourceLine(); err == nil {
+				st = st + fmt.Sprintf("   ```go\n   %s\n   ```\n", source)
+			}
+			if len(f.Context) > 0 {
+				st = st + fmt.Sprintf("   ctx: `%s`\n", formatPairs(f.Context))
+			}
+		}
+		st = st + "\n"
+	}
+
+	if _, err := io.WriteString(w, st); err != nil {
+		return err
+	}
+
+	for i, b := range s.Branches {
+		if _, err := fmt.Fprintf(w, "%s# Error %d of %d\n\n", heading, i+1, len(s.Branches)); err != nil {
+			return err
+		}
+		if err := r.render(w, b, level+2); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LogfmtRenderer renders a StackTrace as a single logfmt line.
+//
+// For example:
+//
+//	error="human friendly error message" ctx.key=value stack.0=pkg.fn:/file.go:12
+type LogfmtRenderer struct{}
+
+// Render implements the Renderer interface.
+func (r LogfmtRenderer) Render(w io.Writer, s *StackTrace) error {
+	_, err := io.WriteString(w, strings.Join(r.pairs("", s), " ")+"\n")
+	return err
+}
+
+func (r LogfmtRenderer) pairs(prefix string, s *StackTrace) []string {
+	pairs := []string{prefix + "error=" + logfmtValue(redactMessage(s.ErrorMsg))}
+
+	if s.Code != OK {
+		pairs = append(pairs, prefix+"code="+s.Code.String())
+	}
+
+	if s.Panic != NotPanicked {
+		pairs = append(pairs, prefix+"panic="+s.Panic.String())
+	}
+
+	ctx := s.SafeCtx()
+	for _, k := range sortedKeys(ctx) {
+		pairs = append(pairs, prefix+"ctx."+logfmtKey(k)+"="+logfmtValue(fmt.Sprint(ctx[k])))
+	}
+
+	for i, f := range s.Stack {
+		pairs = append(pairs, fmt.Sprintf("%sstack.%d=%s", prefix, i,
+			logfmtValue(fmt.Sprintf("%s.%s:%s:%d", f.Package, f.Name, f.File, f.LineNumber)),
+		))
+	}
+
+	for i, b := range s.Branches {
+		pairs = append(pairs, r.pairs(fmt.Sprintf("%serrors.%d.", prefix, i), b)...)
+	}
+
+	return pairs
+}
+
+// codeSuffix returns the Code formatted for display after an error message.
+func codeSuffix(c Code) string {
+	if c == OK {
+		return ""
+	}
+	return " [" + c.String() + "]"
+}
+
+// panicSuffix returns a marker for display after the message of an error that
+// was recovered from a foreign panic. Check panics are the ordinary control
+// flow of this package so they are not marked.
+func panicSuffix(k PanicKind) string {
+	if k != ForeignPanic {
+		return ""
+	}
+	return " (recovered from panic)"
+}
+
+// sourceContext renders the source code surrounding a frame, with a gutter
+// of line numbers and the line of the frame its self marked with a ">".
+func sourceContext(f *StackFrame, n int, indent, gutterColor string) string {
+	lines, start, err := f.SourceLines(n)
+	if err != nil {
+		return ""
+	}
+	reset := ""
+	if gutterColor != "" {
+		reset = ansiReset
+	}
+	width := len(strconv.Itoa(start + len(lines) - 1))
+	str := ""
+	for i, line := range lines {
+		marker := " "
+		if start+i == f.LineNumber {
+			marker = ">"
+		}
+		str = str + fmt.Sprintf("%s%s%s %*d |%s %s\n", indent, gutterColor, marker, width, start+i, reset, line)
+	}
+	return str
+}
+
+// logfmtKey replaces any characters of k that are not valid
+// in a logfmt key, such as spaces, "=" & quotes, with "_".
+func logfmtKey(k string) string {
+	if k == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, k)
+}
+
+func logfmtValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " =\"\t\r\n") {
+		return fmt.Sprintf("%q", v)
+	}
+	return v
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ctxJSON renders ctx as indented JSON along with a description of any
+// problems encountered doing so, this will never fail. see safeCtx
+func ctxJSON(ctx map[string]interface{}) (string, []string) {
+	safe, problems := safeCtx(ctx)
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(safe); err != nil {
+		return "{}", append(problems, fmt.Sprintf("context could not be encoded: %s", err))
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), problems
+}
+
+// formatPairs formats a map as space separated key=value pairs sorted by key.
+// Any values that can not be safely rendered are replaced, see safeCtx
+func formatPairs(ctx map[string]interface{}) string {
+	m, _ := safeCtx(ctx)
+	keys := sortedKeys(m)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%v", k, m[k])
+	}
+	return strings.Join(pairs, " ")
+}
+
+// PrintOption configures PrintTraceTo.
+type PrintOption func(*printOptions)
+
+type printOptions struct {
+	renderer Renderer
+}
+
+// RenderWith sets the Renderer used by PrintTraceTo,
+// by default the TextRenderer is used.
+func RenderWith(r Renderer) PrintOption {
+	return func(o *printOptions) {
+		o.renderer = r
+	}
+}
+
+// PrintTraceTo will print the stack trace for the given error to w.
+//
+// By default this is output in the same format as PrintTrace,
+// supply a different Renderer with the RenderWith option.
+func PrintTraceTo(w io.Writer, err error, opts ...PrintOption) error {
+	o := &printOptions{renderer: TextRenderer{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o.renderer.Render(w, NewStackTrace(err))
+}
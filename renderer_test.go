@@ -0,0 +1,76 @@
+package goerr_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func renderCrash() error {
+	return goerr.WrapWith(errors.New("abc"), goerr.Msg("crashed"), goerr.With("user_id", 123))
+}
+
+func render(t *testing.T, r goerr.Renderer, err error) string {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, goerr.PrintTraceTo(buf, err, goerr.RenderWith(r)))
+	return buf.String()
+}
+
+func TestPrintTraceTo(t *testing.T) {
+	err := renderCrash()
+	buf := &bytes.Buffer{}
+	if assert.NoError(t, goerr.PrintTraceTo(buf, err)) {
+		assert.Equal(t, goerr.NewStackTrace(err).String(), buf.String())
+	}
+}
+
+func TestTextRenderer(t *testing.T) {
+	out := render(t, goerr.TextRenderer{}, renderCrash())
+	lines := strings.Split(out, "\n")
+	assert.Equal(t, "crashed: abc", lines[0])
+	assert.Equal(t, "{", lines[2])
+	assert.Equal(t, "    \"user_id\": 123", lines[3])
+	assert.True(t, strings.HasPrefix(lines[6], "github.com/brad-jones/goerr/v2_test.renderCrash:"))
+	assert.Equal(t, "\treturn goerr.WrapWith(errors.New(\"abc\"), goerr.Msg(\"crashed\"), goerr.With(\"user_id\", 123))", lines[7])
+	assert.Equal(t, "\tctx: user_id=123", lines[8])
+}
+
+func TestANSIRenderer(t *testing.T) {
+	out := render(t, goerr.ANSIRenderer{}, renderCrash())
+	assert.True(t, strings.HasPrefix(out, "\x1b[1m\x1b[31mcrashed: abc\x1b[0m\n\n"))
+	assert.Contains(t, out, "\x1b[33mgithub.com/brad-jones/goerr/v2_test.renderCrash\x1b[0m:")
+}
+
+func TestCompactRenderer(t *testing.T) {
+	out := render(t, goerr.CompactRenderer{}, renderCrash())
+	assert.Equal(t, 1, strings.Count(out, "\n"))
+	assert.True(t, strings.HasPrefix(out, "crashed: abc [user_id=123] at github.com/brad-jones/goerr/v2_test.renderCrash ("))
+
+	out = render(t, goerr.CompactRenderer{}, goerr.Join(errors.New("abc"), errors.New("xyz")))
+	assert.Equal(t, "abc; xyz {(1) abc; (2) xyz}\n", out)
+}
+
+func TestMarkdownRenderer(t *testing.T) {
+	out := render(t, goerr.MarkdownRenderer{}, renderCrash())
+	assert.True(t, strings.HasPrefix(out, "### crashed: abc\n\n```json\n{\n    \"user_id\": 123\n}\n```\n\n1. `github.com/brad-jones/goerr/v2_test.renderCrash` at `"))
+	assert.Contains(t, out, "   ctx: `user_id=123`\n")
+
+	out = render(t, goerr.MarkdownRenderer{}, goerr.Join(errors.New("abc"), errors.New("xyz")))
+	assert.Equal(t, "### abc xyz\n\n#### Error 1 of 2\n\n##### abc\n\n#### Error 2 of 2\n\n##### xyz\n\n", out)
+}
+
+func TestLogfmtRenderer(t *testing.T) {
+	out := render(t, goerr.LogfmtRenderer{}, renderCrash())
+	assert.True(t, strings.HasPrefix(out, "error=\"crashed: abc\" ctx.user_id=123 stack.0=github.com/brad-jones/goerr/v2_test.renderCrash:"))
+
+	out = render(t, goerr.LogfmtRenderer{}, fmt.Errorf("%w", goerr.Join(errors.New("abc"), errors.New("x y"))))
+	assert.Equal(t, "error=\"abc\\nx y\" errors.0.error=abc errors.1.error=\"x y\"\n", out)
+
+	out = render(t, goerr.LogfmtRenderer{}, goerr.WrapWith(errors.New("abc"), goerr.With("my key=\"x\"\n", "v=1")))
+	assert.Contains(t, out, ` ctx.my_key__x__="v=1" `)
+}
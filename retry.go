@@ -0,0 +1,197 @@
+package goerr
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+type retryable int
+
+const (
+	retryUnset retryable = iota
+	retryYes
+	retryNo
+)
+
+// WithRetry marks the error as retryable, or not, see IsRetryable.
+func WithRetry(retry bool) Option {
+	return func(e *Error) {
+		e.retryable = retryNo
+		if retry {
+			e.retryable = retryYes
+		}
+	}
+}
+
+// WithBackoff suggests how long to wait before retrying, see RetryAfter.
+func WithBackoff(d time.Duration) Option {
+	return func(e *Error) {
+		e.backoff = d
+	}
+}
+
+// WithMaxAttempts limits the number of attempts Retry will make once
+// this error has been returned, regardless of the RetryPolicy.
+func WithMaxAttempts(n int) Option {
+	return func(e *Error) {
+		e.maxAttempts = n
+	}
+}
+
+// IsRetryable reports whether the operation that caused err may succeed if
+// it is attempted again.
+//
+// The first error in err's chain that expresses an opinion decides, that is
+// an *Error marked with WithRetry, any error with a Temporary() bool method
+// or any error with a Timeout() bool method that returns true, such as
+// context.DeadlineExceeded & many net.Error values.
+func IsRetryable(err error) bool {
+	retry := false
+	walk(err, func(e error) bool {
+		switch t := e.(type) {
+		case *Error:
+			if t.retryable == retryUnset {
+				return true
+			}
+			retry = t.retryable == retryYes
+			return false
+		case interface{ Temporary() bool }:
+			retry = t.Temporary()
+			return false
+		case interface{ Timeout() bool }:
+			if t.Timeout() {
+				retry = true
+				return false
+			}
+		}
+		return true
+	})
+	return retry
+}
+
+// RetryAfter returns the first backoff suggested by err's chain, either
+// with WithBackoff or by an error with a RetryAfter() time.Duration method.
+// 0 is returned if there is no suggestion.
+func RetryAfter(err error) time.Duration {
+	var d time.Duration
+	walk(err, func(e error) bool {
+		switch t := e.(type) {
+		case *Error:
+			d = t.backoff
+		case interface{ RetryAfter() time.Duration }:
+			d = t.RetryAfter()
+		}
+		return d == 0
+	})
+	return d
+}
+
+// maxAttemptsOf returns the first max attempts set in err's chain or 0.
+func maxAttemptsOf(err error) int {
+	n := 0
+	walk(err, func(e error) bool {
+		if g, ok := e.(*Error); ok {
+			n = g.maxAttempts
+		}
+		return n == 0
+	})
+	return n
+}
+
+// RetryPolicy configures Retry.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times to call the function,
+	// 0 means there is no limit other than that of the context.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry, used when the error
+	// its self does not suggest one. see MinRetryBackoff
+	Backoff time.Duration
+
+	// Multiplier increases the Backoff after each attempt, values below 1 are ignored.
+	Multiplier float64
+
+	// MaxBackoff caps the delay between attempts, 0 means there is no cap.
+	MaxBackoff time.Duration
+}
+
+// MinRetryBackoff is the minimum delay between attempts made by Retry,
+// such that a policy without a Backoff does not retry in a tight loop.
+var MinRetryBackoff = 10 * time.Millisecond
+
+// DefaultRetryPolicy makes 3 attempts with an exponential backoff.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Backoff:     100 * time.Millisecond,
+	Multiplier:  2,
+	MaxBackoff:  10 * time.Second,
+}
+
+// Retry calls fn until it succeeds, it returns an error that is not
+// retryable, the attempts of the policy or the error are exhausted or
+// ctx is done. See IsRetryable & RetryAfter.
+//
+// The error of every failed attempt is traced to the caller of Retry with
+// an "attempt" context value and the attempts are joined, as per Join, into
+// the returned error. If ctx is done its error is joined last.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	pc, _, _, _ := runtime.Caller(1)
+
+	var attempts []error
+	backoff := policy.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		attempts = append(attempts, &Error{
+			innerErr: err,
+			caller:   pc,
+			fields:   []field{{"attempt", attempt}},
+		})
+
+		limit := policy.MaxAttempts
+		if n := maxAttemptsOf(err); n > 0 && (limit == 0 || n < limit) {
+			limit = n
+		}
+		if !IsRetryable(err) || (limit > 0 && attempt >= limit) {
+			return retryFailed(pc, attempt, attempts)
+		}
+
+		delay := RetryAfter(err)
+		if delay == 0 {
+			delay = backoff
+		}
+		if policy.Multiplier > 1 {
+			backoff = time.Duration(float64(backoff) * policy.Multiplier)
+		}
+		if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+			delay = policy.MaxBackoff
+		}
+		if delay < MinRetryBackoff {
+			delay = MinRetryBackoff
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return retryFailed(pc, attempt, append(attempts, context.Cause(ctx)))
+		case <-timer.C:
+		}
+	}
+}
+
+func retryFailed(pc uintptr, attempts int, errs []error) error {
+	msg := "failed after 1 attempt"
+	if attempts > 1 {
+		msg = fmt.Sprintf("failed after %d attempts", attempts)
+	}
+	return &Error{
+		message:  msg,
+		innerErr: Join(errs...),
+		caller:   pc,
+	}
+}
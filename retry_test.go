@@ -0,0 +1,99 @@
+package goerr_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type temporaryError struct{ temporary bool }
+
+func (e temporaryError) Error() string   { return "temporary" }
+func (e temporaryError) Temporary() bool { return e.temporary }
+
+func TestIsRetryable(t *testing.T) {
+	assert.False(t, goerr.IsRetryable(nil))
+	assert.False(t, goerr.IsRetryable(goerr.New("abc")))
+	assert.True(t, goerr.IsRetryable(goerr.WrapWith(goerr.New("abc"), goerr.WithRetry(true))))
+	assert.True(t, goerr.IsRetryable(fmt.Errorf("%w", goerr.WrapWith(goerr.New("abc"), goerr.WithRetry(true)))))
+	assert.False(t, goerr.IsRetryable(goerr.WrapWith(temporaryError{true}, goerr.WithRetry(false))))
+	assert.True(t, goerr.IsRetryable(goerr.Wrap(temporaryError{true})))
+	assert.False(t, goerr.IsRetryable(goerr.Wrap(temporaryError{false})))
+	assert.True(t, goerr.IsRetryable(goerr.Wrap(context.DeadlineExceeded)))
+	assert.True(t, goerr.IsRetryable(goerr.Join(goerr.New("abc"), goerr.WrapWith(goerr.New("def"), goerr.WithRetry(true)))))
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), goerr.RetryAfter(goerr.New("abc")))
+	e := goerr.WrapWith(goerr.WrapWith(goerr.New("abc"), goerr.WithBackoff(time.Second)), goerr.WithRetry(true))
+	assert.Equal(t, time.Second, goerr.RetryAfter(e))
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	err := goerr.Retry(context.Background(), goerr.RetryPolicy{MaxAttempts: 5}, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return goerr.WrapWith(goerr.New("flaky"), goerr.WithRetry(true))
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryMinBackoff(t *testing.T) {
+	calls := 0
+	start := time.Now()
+	err := goerr.Retry(context.Background(), goerr.RetryPolicy{}, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return goerr.WrapWith(goerr.New("flaky"), goerr.WithRetry(true))
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.True(t, time.Since(start) >= 2*goerr.MinRetryBackoff)
+}
+
+func TestRetryExhausted(t *testing.T) {
+	calls := 0
+	err := goerr.Retry(context.Background(), goerr.RetryPolicy{MaxAttempts: 5}, func(ctx context.Context) error {
+		calls++
+		return goerr.WrapWith(goerr.New("flaky"), goerr.WithRetry(true), goerr.WithMaxAttempts(2))
+	})
+	assert.Equal(t, 2, calls)
+	assert.EqualError(t, err, "failed after 2 attempts: flaky\nflaky")
+
+	st := goerr.NewStackTrace(err)
+	if assert.Equal(t, 2, len(st.Branches)) {
+		assert.Equal(t, 2, st.Branches[1].ErrorCtx["attempt"])
+		assert.Equal(t, "TestRetryExhausted", st.Branches[1].Stack[len(st.Branches[1].Stack)-1].Name)
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	calls := 0
+	err := goerr.Retry(context.Background(), goerr.DefaultRetryPolicy, func(ctx context.Context) error {
+		calls++
+		return errors.New("fatal")
+	})
+	assert.Equal(t, 1, calls)
+	assert.EqualError(t, err, "failed after 1 attempt: fatal")
+}
+
+func TestRetryContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	err := goerr.Retry(ctx, goerr.RetryPolicy{Backoff: time.Hour}, func(ctx context.Context) error {
+		cancel()
+		return goerr.WrapWith(goerr.New("flaky"), goerr.WithRetry(true))
+	})
+	assert.True(t, goerr.Is(err, context.Canceled))
+	assert.EqualError(t, err, "failed after 1 attempt: flaky\ncontext canceled")
+}
@@ -0,0 +1,123 @@
+package goerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// MaxCtxKeys is the maximum number of context values that will be
+// rendered for a single error or frame, any more are truncated.
+var MaxCtxKeys = 100
+
+// MaxCtxValueLen is the maximum length of a single rendered
+// context value, longer values are truncated.
+var MaxCtxValueLen = 1024
+
+// safeCtx returns a copy of ctx that can always be encoded as JSON,
+// along with a description of any problems encountered doing so.
+//
+// Sensitive values are redacted, values that can not be encoded are
+// formatted with %+v instead, values that contain cycles are replaced
+// entirely & any large maps or values are truncated.
+func safeCtx(ctx map[string]interface{}) (map[string]interface{}, []string) {
+	if ctx == nil {
+		return nil, nil
+	}
+	ctx = redactCtx(ctx)
+
+	var problems []string
+	keys := sortedKeys(ctx)
+	if len(keys) > MaxCtxKeys {
+		problems = append(problems, fmt.Sprintf("%d context values were truncated", len(keys)-MaxCtxKeys))
+		keys = keys[:MaxCtxKeys]
+	}
+
+	out := make(map[string]interface{}, len(keys))
+	for _, k := range keys {
+		v, problem := safeValue(ctx[k])
+		if problem != "" {
+			problems = append(problems, fmt.Sprintf("context value %q %s", k, problem))
+		}
+		out[k] = v
+	}
+
+	return out, problems
+}
+
+func safeValue(v interface{}) (safe interface{}, problem string) {
+	defer func() {
+		if r := recover(); r != nil {
+			safe = "<panic>"
+			problem = fmt.Sprintf("panicked while encoding: %v", r)
+		}
+	}()
+
+	j, err := json.Marshal(v)
+	if err == nil {
+		if len(j) > MaxCtxValueLen {
+			return truncate(string(j)), "was truncated"
+		}
+		return v, ""
+	}
+
+	problem = fmt.Sprintf("could not be encoded: %s", err)
+	if hasCycle(reflect.ValueOf(v), map[uintptr]bool{}, 0) {
+		return "<cycle>", problem
+	}
+	return truncate(fmt.Sprintf("%+v", v)), problem
+}
+
+func truncate(s string) string {
+	if len(s) > MaxCtxValueLen {
+		return s[:MaxCtxValueLen] + "...(truncated)"
+	}
+	return s
+}
+
+// hasCycle reports if v references its self, values nested
+// too deeply to reasonably render are also considered cyclic.
+func hasCycle(v reflect.Value, path map[uintptr]bool, depth int) bool {
+	if depth > 64 {
+		return true
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return false
+		}
+		ptr := v.Pointer()
+		if path[ptr] {
+			return true
+		}
+		path[ptr] = true
+		defer delete(path, ptr)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return hasCycle(v.Elem(), path, depth+1)
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if hasCycle(iter.Value(), path, depth+1) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if hasCycle(v.Index(i), path, depth+1) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if hasCycle(v.Field(i), path, depth+1) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
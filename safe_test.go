@@ -0,0 +1,71 @@
+package goerr_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStackTraceStringUnencodableCtx(t *testing.T) {
+	err := goerr.WrapWith(goerr.New("abc"), goerr.With("fn", func() {}), goerr.With("ok", 1))
+	st := goerr.NewStackTrace(err)
+	var out string
+	assert.NotPanics(t, func() { out = st.String() })
+	assert.Contains(t, out, "\n    \"ok\": 1\n}\ngoerr: context value \"fn\" could not be encoded: json: unsupported type: func()\n\n")
+
+	j, jerr := json.Marshal(st)
+	if assert.NoError(t, jerr) {
+		assert.Contains(t, string(j), `"ok":1`)
+	}
+}
+
+func TestStackTraceStringCyclicCtx(t *testing.T) {
+	cyclic := map[string]interface{}{}
+	cyclic["self"] = cyclic
+	err := goerr.WrapWith(goerr.New("abc"), goerr.With("cyclic", cyclic))
+	st := goerr.NewStackTrace(err)
+	var out string
+	assert.NotPanics(t, func() { out = st.String() })
+	assert.Contains(t, out, "\"cyclic\": \"<cycle>\"")
+	assert.Contains(t, out, "\tctx: cyclic=<cycle>\n")
+	assert.Contains(t, out, "goerr: context value \"cyclic\" could not be encoded: json: unsupported value:")
+}
+
+func TestStackTraceStringLargeCtx(t *testing.T) {
+	ctx := map[string]interface{}{"big": strings.Repeat("a", goerr.MaxCtxValueLen*2)}
+	for i := 0; i < goerr.MaxCtxKeys+5; i++ {
+		ctx[fmt.Sprintf("key%03d", i)] = i
+	}
+	st := &goerr.StackTrace{ErrorMsg: "abc", ErrorCtx: ctx}
+	out := st.String()
+	assert.Contains(t, out, "goerr: 6 context values were truncated\n")
+	assert.Contains(t, out, "goerr: context value \"big\" was truncated\n")
+	assert.Contains(t, out, "...(truncated)")
+	assert.NotContains(t, out, "key104")
+}
+
+func TestStackTraceSafeCtx(t *testing.T) {
+	err := goerr.WrapWith(goerr.New("abc"),
+		goerr.With("fn", func() {}),
+		goerr.With("password", "hunter2"),
+		goerr.With("big", strings.Repeat("a", goerr.MaxCtxValueLen*2)),
+		goerr.With("ok", 1),
+	)
+	st := goerr.NewStackTrace(err)
+	for _, ctx := range []map[string]interface{}{st.SafeCtx(), st.Stack[0].SafeCtx()} {
+		assert.Equal(t, 1, ctx["ok"])
+		assert.Equal(t, goerr.Redacted, ctx["password"])
+		assert.IsType(t, "", ctx["fn"])
+		assert.Less(t, len(ctx["big"].(string)), goerr.MaxCtxValueLen*2)
+		_, jerr := json.Marshal(ctx)
+		assert.NoError(t, jerr)
+	}
+
+	manual := &goerr.StackTrace{ErrorMsg: "abc", ErrorCtx: map[string]interface{}{"token": "xyz"}}
+	assert.Equal(t, map[string]interface{}{"token": goerr.Redacted}, manual.SafeCtx())
+	assert.Nil(t, (&goerr.StackTrace{}).SafeCtx())
+}
@@ -0,0 +1,80 @@
+package goerr
+
+import (
+	"fmt"
+)
+
+// SentinelError is an error with a stable identity that can be compared
+// with Is no matter how many times it has been raised or wrapped,
+// create new instances with Sentinel.
+type SentinelError struct {
+	format string
+	opts   []Option
+}
+
+// Sentinel is the constructor for SentinelError.
+//
+// The format is a fmt template that is filled in each time the error is
+// raised with With, any Options are applied to every raised error.
+//
+// For example:
+//
+//	var errNotFound = goerr.Sentinel("user %s not found", goerr.WithCode(goerr.NotFound))
+//
+//	func find(id string) error {
+//		return errNotFound.With(id)
+//	}
+//
+//	goerr.Is(find("123"), errNotFound) // true
+func Sentinel(format string, opts ...Option) *SentinelError {
+	return &SentinelError{format: format, opts: opts}
+}
+
+// Error implements the stdlib error interface, returning the unfilled format.
+func (s *SentinelError) Error() string {
+	return s.format
+}
+
+// With raises the sentinel, filling in its format with args.
+//
+// The returned error is traced to the site that called With rather than
+// where the sentinel was declared and Is reports it as matching s.
+func (s *SentinelError) With(args ...interface{}) *Error {
+	return TraceWith(1, &raisedError{sentinel: s, args: args}, s.opts...)
+}
+
+// SentinelOf returns the SentinelError that the first error in err's chain
+// was raised from, or the SentinelError its self should it be in the chain.
+// nil is returned if there is none.
+func SentinelOf(err error) *SentinelError {
+	var sentinel *SentinelError
+	walk(err, func(e error) bool {
+		switch t := e.(type) {
+		case *raisedError:
+			sentinel = t.sentinel
+		case *SentinelError:
+			sentinel = t
+		}
+		return sentinel == nil
+	})
+	return sentinel
+}
+
+// raisedError is a single use of a SentinelError.
+type raisedError struct {
+	sentinel *SentinelError
+	args     []interface{}
+}
+
+// Error implements the stdlib error interface.
+func (r *raisedError) Error() string {
+	if len(r.args) == 0 {
+		return r.sentinel.format
+	}
+	return fmt.Sprintf(r.sentinel.format, r.args...)
+}
+
+// Is reports whether target is the sentinel that was raised.
+func (r *raisedError) Is(target error) bool {
+	return target == r.sentinel
+}
@@ -0,0 +1,43 @@
+package goerr_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var errUserNotFound = goerr.Sentinel("user %s not found", goerr.WithCode(goerr.NotFound))
+
+func findUser(id string) error {
+	return errUserNotFound.With(id)
+}
+
+func TestSentinel(t *testing.T) {
+	err := findUser("123")
+	assert.EqualError(t, err, "user 123 not found")
+	assert.True(t, goerr.Is(err, errUserNotFound))
+	assert.True(t, goerr.Is(fmt.Errorf("%w", goerr.Wrap(err, "outer")), errUserNotFound))
+	assert.False(t, goerr.Is(err, goerr.Sentinel("user %s not found")))
+	assert.Equal(t, goerr.NotFound, goerr.CodeOf(err))
+
+	var e *goerr.Error
+	if assert.True(t, goerr.As(err, &e)) {
+		assert.Equal(t, "findUser", e.Frame().Name)
+	}
+}
+
+func TestSentinelOf(t *testing.T) {
+	assert.Equal(t, errUserNotFound, goerr.SentinelOf(goerr.Wrap(findUser("123"), "outer")))
+	assert.Equal(t, errUserNotFound, goerr.SentinelOf(goerr.Wrap(errUserNotFound)))
+	assert.Nil(t, goerr.SentinelOf(goerr.New("abc")))
+	assert.Nil(t, goerr.SentinelOf(nil))
+}
+
+func TestSentinelWithoutArgs(t *testing.T) {
+	errFoo := goerr.Sentinel("expecting 100%")
+	assert.EqualError(t, errFoo.With(), "expecting 100%")
+	assert.EqualError(t, errFoo, "expecting 100%")
+	assert.True(t, goerr.Is(goerr.Wrap(errFoo), errFoo))
+}
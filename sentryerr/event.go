@@ -0,0 +1,207 @@
+/*
+Package sentryerr exports goerr traces to Sentry, or any compatible error
+tracker such as GlitchTip, using only the standard library.
+
+	exporter, err := sentryerr.NewExporter("https://key@sentry.example.com/42",
+		sentryerr.WithSpool("/var/spool/myapp"),
+	)
+	defer exporter.Close(context.Background())
+
+	exporter.Export(err)
+
+Events are queued and sent in batches in the background, any envelope that
+can not be delivered is written to the spool directory, if configured, and
+sent again on the next flush. The spool is bounded in size and envelopes
+are dropped after too many attempts or once they are too old, see
+WithMaxSpoolSize, WithMaxSpoolAttempts & WithMaxSpoolAge.
+*/
+package sentryerr
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/brad-jones/goerr/v2"
+)
+
+// Event is a Sentry event, see https://develop.sentry.dev/sdk/event-payloads/
+type Event struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   time.Time              `json:"timestamp"`
+	Platform    string                 `json:"platform"`
+	Level       string                 `json:"level"`
+	Logger      string                 `json:"logger,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	Fingerprint []string               `json:"fingerprint,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+	Exception   *Exceptions            `json:"exception,omitempty"`
+}
+
+// Exceptions is the exception interface of an Event.
+type Exceptions struct {
+	Values []*Exception `json:"values"`
+}
+
+// Exception is a single error of an Event, there is one for the trace its
+// self, one for each meaningful error in its cause chain and, for a
+// goerr.MultiError, those of each branch.
+type Exception struct {
+	Type       string     `json:"type"`
+	Value      string     `json:"value"`
+	Module     string     `json:"module,omitempty"`
+	Stacktrace *Stack     `json:"stacktrace,omitempty"`
+	Mechanism  *Mechanism `json:"mechanism,omitempty"`
+}
+
+// Mechanism describes how an Exception relates to the others of an Event.
+type Mechanism struct {
+	Type             string `json:"type"`
+	Handled          bool   `json:"handled"`
+	Source           string `json:"source,omitempty"`
+	ExceptionID      int    `json:"exception_id"`
+	ParentID         *int   `json:"parent_id,omitempty"`
+	IsExceptionGroup bool   `json:"is_exception_group,omitempty"`
+}
+
+// Stack is the stack trace of an Exception, the frames are
+// ordered from the root of the program to the cause.
+type Stack struct {
+	Frames []*Frame `json:"frames"`
+}
+
+// Frame is a single stack frame of an Exception.
+type Frame struct {
+	Function    string                 `json:"function"`
+	Module      string                 `json:"module,omitempty"`
+	Filename    string                 `json:"filename,omitempty"`
+	AbsPath     string                 `json:"abs_path,omitempty"`
+	Lineno      int                    `json:"lineno,omitempty"`
+	ContextLine string                 `json:"context_line,omitempty"`
+	InApp       bool                   `json:"in_app"`
+	Vars        map[string]interface{} `json:"vars,omitempty"`
+}
+
+// NewEvent converts a StackTrace into an Event.
+//
+// The fingerprint of the trace is used as the fingerprint of the event, the
+// context values are sent as extra data, the cause chain is sent as chained
+// exceptions and the branches of a MultiError as an exception group.
+func NewEvent(st *goerr.StackTrace) (*Event, error) {
+	id, err := newEventID()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Event{
+		EventID:     id,
+		Timestamp:   time.Now().UTC(),
+		Platform:    "go",
+		Level:       "error",
+		Logger:      "goerr",
+		Fingerprint: []string{st.Fingerprint()},
+		Extra:       st.SafeCtx(),
+		Exception:   &Exceptions{},
+	}
+	if st.Code != goerr.OK || st.Panic != goerr.NotPanicked {
+		e.Tags = map[string]string{}
+		if st.Code != goerr.OK {
+			e.Tags["code"] = st.Code.String()
+		}
+		if st.Panic != goerr.NotPanicked {
+			e.Tags["panic"] = st.Panic.String()
+		}
+	}
+
+	e.Exception.Values = exceptions(st, nil, "", 0)
+
+	// Sentry expects the values from the oldest exception to the newest
+	for i, j := 0, len(e.Exception.Values)-1; i < j; i, j = i+1, j-1 {
+		e.Exception.Values[i], e.Exception.Values[j] = e.Exception.Values[j], e.Exception.Values[i]
+	}
+
+	return e, nil
+}
+
+// exceptions converts a trace & each of its branches into exceptions, depth
+// first from the newest exception, assigning exception ids from id onwards.
+//
+// Each error in the cause chain of the trace that names a different kind
+// of failure, as per goerr.TypeName, is chained to the one before it such
+// that Sentry shows, for example, a *fs.PathError caused by a syscall.Errno.
+func exceptions(st *goerr.StackTrace, parent *int, source string, id int) []*Exception {
+	ex := &Exception{
+		Type:  goerr.TypeName(st.Error),
+		Value: st.ErrorMsg,
+		Mechanism: &Mechanism{
+			Type:        "generic",
+			Handled:     st.Panic != goerr.ForeignPanic,
+			Source:      source,
+			ExceptionID: id,
+			ParentID:    parent,
+		},
+	}
+	if parent != nil {
+		ex.Mechanism.Type = "chained"
+	}
+
+	if len(st.Stack) > 0 {
+		ex.Stacktrace = &Stack{}
+		for i := len(st.Stack) - 1; i >= 0; i-- {
+			f := st.Stack[i]
+			src, _ := f.SourceLine()
+			ex.Stacktrace.Frames = append(ex.Stacktrace.Frames, &Frame{
+				Function:    f.Name,
+				Module:      f.Package,
+				Filename:    f.File,
+				AbsPath:     f.File,
+				Lineno:      f.LineNumber,
+				ContextLine: src,
+				InApp:       f.Wrapped,
+				Vars:        f.SafeCtx(),
+			})
+		}
+		ex.Module = st.Stack[0].Package
+	}
+
+	out := []*Exception{ex}
+	last := ex
+	for e := goerr.Unwrap(st.Error); e != nil; e = goerr.Unwrap(e) {
+		name := goerr.TypeName(e)
+		if name == last.Type {
+			continue
+		}
+		parentID := last.Mechanism.ExceptionID
+		last = &Exception{
+			Type:  name,
+			Value: goerr.NewStackTrace(e).ErrorMsg,
+			Mechanism: &Mechanism{
+				Type:        "chained",
+				Handled:     ex.Mechanism.Handled,
+				Source:      "cause",
+				ExceptionID: id + len(out),
+				ParentID:    &parentID,
+			},
+		}
+		out = append(out, last)
+	}
+
+	last.Mechanism.IsExceptionGroup = len(st.Branches) > 0
+	self := last.Mechanism.ExceptionID
+	for i, b := range st.Branches {
+		branch := exceptions(b, &self, fmt.Sprintf("errors[%d]", i), id+len(out))
+		out = append(out, branch...)
+	}
+	return out
+}
+
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
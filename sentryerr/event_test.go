@@ -0,0 +1,100 @@
+package sentryerr_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/brad-jones/goerr/v2/sentryerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadUser() error {
+	return goerr.WrapWith(errors.New("not found"),
+		goerr.Msg("loading user"),
+		goerr.With("user_id", 1),
+		goerr.With("token", "s3cr3t"),
+		goerr.WithCode(goerr.NotFound),
+	)
+}
+
+func TestNewEvent(t *testing.T) {
+	st := goerr.NewStackTrace(goerr.Wrap(loadUser()))
+	e, err := sentryerr.NewEvent(st)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 32, len(e.EventID))
+	assert.Equal(t, "go", e.Platform)
+	assert.Equal(t, "error", e.Level)
+	assert.Equal(t, []string{st.Fingerprint()}, e.Fingerprint)
+	assert.Equal(t, map[string]string{"code": "NotFound"}, e.Tags)
+	assert.Equal(t, 1, e.Extra["user_id"])
+	assert.Equal(t, goerr.Redacted, e.Extra["token"])
+
+	if assert.Equal(t, 2, len(e.Exception.Values)) {
+		ex := e.Exception.Values[1]
+		assert.Equal(t, "NotFound", ex.Type)
+		assert.Equal(t, "loading user: not found", ex.Value)
+		assert.True(t, ex.Mechanism.Handled)
+		assert.Equal(t, 0, ex.Mechanism.ExceptionID)
+		if assert.Equal(t, 2, len(ex.Stacktrace.Frames)) {
+			root, cause := ex.Stacktrace.Frames[0], ex.Stacktrace.Frames[1]
+			assert.Equal(t, "TestNewEvent", root.Function)
+			assert.Equal(t, "loadUser", cause.Function)
+			assert.Equal(t, "github.com/brad-jones/goerr/v2/sentryerr_test", cause.Module)
+			assert.Equal(t, `return goerr.WrapWith(errors.New("not found"),`, cause.ContextLine)
+			assert.Equal(t, goerr.Redacted, cause.Vars["token"])
+			assert.True(t, cause.InApp)
+		}
+
+		chained := e.Exception.Values[0]
+		assert.Equal(t, "*errors.errorString", chained.Type)
+		assert.Equal(t, "not found", chained.Value)
+		assert.Equal(t, "chained", chained.Mechanism.Type)
+		assert.Equal(t, "cause", chained.Mechanism.Source)
+		assert.Equal(t, 1, chained.Mechanism.ExceptionID)
+		assert.Equal(t, 0, *chained.Mechanism.ParentID)
+		assert.Nil(t, chained.Stacktrace)
+	}
+}
+
+func TestNewEventChained(t *testing.T) {
+	_, cause := os.Open("/does/not/exist")
+	st := goerr.NewStackTrace(goerr.Wrap(fmt.Errorf("opening: %w", cause)))
+	e, err := sentryerr.NewEvent(st)
+	if assert.NoError(t, err) && assert.Equal(t, 2, len(e.Exception.Values)) {
+		assert.Equal(t, "*fs.PathError", e.Exception.Values[1].Type)
+		assert.Equal(t, "opening: open /does/not/exist: no such file or directory", e.Exception.Values[1].Value)
+		assert.Equal(t, "syscall.Errno", e.Exception.Values[0].Type)
+		assert.Equal(t, "no such file or directory", e.Exception.Values[0].Value)
+	}
+}
+
+func TestNewEventMultiError(t *testing.T) {
+	st := goerr.NewStackTrace(goerr.Join(loadUser(), goerr.Wrap(errors.New("timeout"))))
+	e, err := sentryerr.NewEvent(st)
+	if assert.NoError(t, err) && assert.Equal(t, 4, len(e.Exception.Values)) {
+		group := e.Exception.Values[3]
+		assert.Equal(t, "*goerr.MultiError", group.Type)
+		assert.True(t, group.Mechanism.IsExceptionGroup)
+		assert.Equal(t, 0, group.Mechanism.ExceptionID)
+
+		first := e.Exception.Values[2]
+		assert.Equal(t, "loading user: not found", first.Value)
+		assert.Equal(t, "chained", first.Mechanism.Type)
+		assert.Equal(t, "errors[0]", first.Mechanism.Source)
+		assert.Equal(t, 0, *first.Mechanism.ParentID)
+		assert.Equal(t, 1, first.Mechanism.ExceptionID)
+		assert.Equal(t, 1, *e.Exception.Values[1].Mechanism.ParentID)
+		assert.Equal(t, 2, e.Exception.Values[1].Mechanism.ExceptionID)
+
+		second := e.Exception.Values[0]
+		assert.Equal(t, "timeout", second.Value)
+		assert.Equal(t, "errors[1]", second.Mechanism.Source)
+		assert.Equal(t, 0, *second.Mechanism.ParentID)
+		assert.Equal(t, 3, second.Mechanism.ExceptionID)
+	}
+}
@@ -0,0 +1,458 @@
+package sentryerr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brad-jones/goerr/v2"
+)
+
+// DSN is a parsed Sentry DSN, eg: https://public-key@sentry.example.com/42
+type DSN struct {
+	raw       string
+	publicKey string
+	envelope  string
+}
+
+// ParseDSN parses a Sentry DSN.
+func ParseDSN(dsn string) (*DSN, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid dsn scheme %q", u.Scheme)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("dsn is missing the public key")
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	project := path[i+1:]
+	if project == "" {
+		return nil, fmt.Errorf("dsn is missing the project id")
+	}
+	return &DSN{
+		raw:       dsn,
+		publicKey: u.User.Username(),
+		envelope:  fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, path[:i], project),
+	}, nil
+}
+
+// String returns the DSN as it was given to ParseDSN.
+func (d *DSN) String() string {
+	return d.raw
+}
+
+// EnvelopeURL returns the URL that envelopes are POSTed to.
+func (d *DSN) EnvelopeURL() string {
+	return d.envelope
+}
+
+// Option configures an Exporter.
+type Option func(*Exporter)
+
+// WithHTTPClient sets the client used to send envelopes, by default
+// a client with a timeout of 10 seconds is used.
+func WithHTTPClient(client *http.Client) Option {
+	return func(e *Exporter) {
+		e.client = client
+	}
+}
+
+// WithBatchSize sets the number of queued events that triggers a flush,
+// the default is 10.
+func WithBatchSize(n int) Option {
+	return func(e *Exporter) {
+		e.batchSize = n
+	}
+}
+
+// WithFlushInterval sets how often queued events are flushed in the
+// background, the default is 5 seconds, 0 disables background flushing.
+func WithFlushInterval(d time.Duration) Option {
+	return func(e *Exporter) {
+		e.interval = d
+	}
+}
+
+// WithSpool sets a directory that envelopes which could not be delivered
+// are written to, they are sent again on the next flush.
+func WithSpool(dir string) Option {
+	return func(e *Exporter) {
+		e.spool = dir
+	}
+}
+
+// WithMaxSpoolSize sets the maximum total size in bytes of the spooled
+// envelopes, the oldest envelopes are dropped to make room for new ones.
+// The default is 10 MiB.
+func WithMaxSpoolSize(size int64) Option {
+	return func(e *Exporter) {
+		e.maxSpoolSize = size
+	}
+}
+
+// WithMaxSpoolAttempts sets the number of failed attempts to send an envelope
+// after which it is dropped from the spool, the default is 10.
+func WithMaxSpoolAttempts(n int) Option {
+	return func(e *Exporter) {
+		e.maxSpoolAttempts = n
+	}
+}
+
+// WithMaxSpoolAge sets how long an envelope is kept in the spool
+// before it is dropped, the default is 24 hours.
+func WithMaxSpoolAge(d time.Duration) Option {
+	return func(e *Exporter) {
+		e.maxSpoolAge = d
+	}
+}
+
+// WithEnvironment sets the environment of every event, eg: production
+func WithEnvironment(env string) Option {
+	return func(e *Exporter) {
+		e.environment = env
+	}
+}
+
+// WithRelease sets the release of every event, eg: myapp@1.2.3
+func WithRelease(release string) Option {
+	return func(e *Exporter) {
+		e.release = release
+	}
+}
+
+// Exporter sends goerr traces to Sentry, create new instances with NewExporter.
+type Exporter struct {
+	dsn              *DSN
+	client           *http.Client
+	batchSize        int
+	interval         time.Duration
+	spool            string
+	maxSpoolSize     int64
+	maxSpoolAttempts int
+	maxSpoolAge      time.Duration
+	environment      string
+	release          string
+
+	mu     sync.Mutex
+	queue  [][]byte
+	sendMu sync.Mutex
+	seq    int
+	kick   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	closed bool
+}
+
+// NewExporter is the constructor for Exporter.
+//
+// A background goroutine flushes queued events, call Close to stop it.
+func NewExporter(dsn string, opts ...Option) (*Exporter, error) {
+	d, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	e := &Exporter{
+		dsn:              d,
+		client:           &http.Client{Timeout: 10 * time.Second},
+		batchSize:        10,
+		interval:         5 * time.Second,
+		maxSpoolSize:     10 << 20,
+		maxSpoolAttempts: 10,
+		maxSpoolAge:      24 * time.Hour,
+		kick:             make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.spool != "" {
+		if err := os.MkdirAll(e.spool, 0o700); err != nil {
+			return nil, err
+		}
+	}
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+	e.wg.Add(1)
+	go e.loop()
+	return e, nil
+}
+
+// Export queues err to be sent, it returns the id of the event.
+func (e *Exporter) Export(err error) (string, error) {
+	return e.ExportTrace(goerr.NewStackTrace(err))
+}
+
+// ExportTrace queues st to be sent, it returns the id of the event.
+func (e *Exporter) ExportTrace(st *goerr.StackTrace) (string, error) {
+	event, err := NewEvent(st)
+	if err != nil {
+		return "", err
+	}
+	event.Environment = e.environment
+	event.Release = e.release
+	envelope, err := e.envelope(event)
+	if err != nil {
+		return "", err
+	}
+
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return "", fmt.Errorf("exporter is closed")
+	}
+	e.queue = append(e.queue, envelope)
+	full := len(e.queue) >= e.batchSize
+	e.mu.Unlock()
+
+	if full {
+		select {
+		case e.kick <- struct{}{}:
+		default:
+		}
+	}
+	return event.EventID, nil
+}
+
+// Flush sends any queued events & spooled envelopes.
+//
+// Envelopes that can not be delivered are spooled, if a spool is configured,
+// otherwise they are dropped. Should ctx be done any envelopes that were not
+// sent remain queued. The first delivery error is returned.
+func (e *Exporter) Flush(ctx context.Context) error {
+	e.sendMu.Lock()
+	defer e.sendMu.Unlock()
+
+	e.mu.Lock()
+	queue := e.queue
+	e.queue = nil
+	e.mu.Unlock()
+
+	var first error
+	for _, spooled := range e.spooled() {
+		if ctx.Err() != nil {
+			break
+		}
+		if e.expired(spooled) {
+			os.Remove(spooled.path)
+			continue
+		}
+		envelope, err := os.ReadFile(spooled.path)
+		if err != nil {
+			continue
+		}
+		if err := e.send(ctx, envelope); err != nil {
+			if first == nil {
+				first = err
+			}
+			e.respool(spooled)
+			continue
+		}
+		os.Remove(spooled.path)
+	}
+	for i, envelope := range queue {
+		if ctx.Err() != nil {
+			e.requeue(queue[i:])
+			if first == nil {
+				first = ctx.Err()
+			}
+			break
+		}
+		if err := e.send(ctx, envelope); err != nil {
+			if first == nil {
+				first = err
+			}
+			if ctx.Err() != nil {
+				e.requeue(queue[i:])
+				break
+			}
+			e.spoolEnvelope(envelope)
+		}
+	}
+	return first
+}
+
+// requeue puts envelopes that were not sent back at the front of the queue.
+func (e *Exporter) requeue(envelopes [][]byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.queue = append(append([][]byte{}, envelopes...), e.queue...)
+}
+
+// Close stops the background flushing, cancelling any flush that is in
+// progress, and then flushes any queued events with ctx.
+func (e *Exporter) Close(ctx context.Context) error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	e.mu.Unlock()
+
+	e.cancel()
+	e.wg.Wait()
+	return e.Flush(ctx)
+}
+
+func (e *Exporter) loop() {
+	defer e.wg.Done()
+	var tick <-chan time.Time
+	if e.interval > 0 {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-e.kick:
+		case <-tick:
+		}
+		e.Flush(e.ctx)
+	}
+}
+
+// envelope encodes an event as an envelope with a single event item,
+// see https://develop.sentry.dev/sdk/envelopes/
+func (e *Exporter) envelope(event *Event) ([]byte, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	header, err := json.Marshal(map[string]interface{}{
+		"event_id": event.EventID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339),
+		"dsn":      e.dsn.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	item, err := json.Marshal(map[string]interface{}{
+		"type":   "event",
+		"length": len(payload),
+	})
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	buf.Write(header)
+	buf.WriteByte('\n')
+	buf.Write(item)
+	buf.WriteByte('\n')
+	buf.Write(payload)
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func (e *Exporter) send(ctx context.Context, envelope []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.dsn.EnvelopeURL(), bytes.NewReader(envelope))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf(
+		"Sentry sentry_version=7, sentry_client=goerr/2, sentry_key=%s", e.dsn.publicKey,
+	))
+	res, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("sentry responded with %s", res.Status)
+	}
+	return nil
+}
+
+// spoolEnvelope must be called with sendMu held.
+func (e *Exporter) spoolEnvelope(envelope []byte) {
+	if e.spool == "" || int64(len(envelope)) > e.maxSpoolSize {
+		return
+	}
+
+	// Drop the oldest envelopes to keep the spool within its maximum size
+	spooled := e.spooled()
+	size := int64(len(envelope))
+	for _, s := range spooled {
+		size += s.size
+	}
+	for i := 0; size > e.maxSpoolSize && i < len(spooled); i++ {
+		os.Remove(spooled[i].path)
+		size -= spooled[i].size
+	}
+
+	e.seq++
+	name := fmt.Sprintf("%020d-%06d-%d.envelope", time.Now().UnixNano(), e.seq, 1)
+	tmp := filepath.Join(e.spool, name+".tmp")
+	if err := os.WriteFile(tmp, envelope, 0o600); err != nil {
+		return
+	}
+	os.Rename(tmp, filepath.Join(e.spool, name))
+}
+
+// spooledEnvelope is an envelope in the spool, the name of the file records
+// when it was first spooled & how many attempts have been made to send it.
+type spooledEnvelope struct {
+	path     string
+	size     int64
+	created  time.Time
+	seq      int
+	attempts int
+}
+
+// expired reports if a spooled envelope should be dropped without sending it.
+func (e *Exporter) expired(s *spooledEnvelope) bool {
+	return s.attempts >= e.maxSpoolAttempts || time.Since(s.created) > e.maxSpoolAge
+}
+
+// respool records another failed attempt to send a spooled envelope,
+// it must be called with sendMu held.
+func (e *Exporter) respool(s *spooledEnvelope) {
+	if s.attempts+1 >= e.maxSpoolAttempts {
+		os.Remove(s.path)
+		return
+	}
+	name := fmt.Sprintf("%020d-%06d-%d.envelope", s.created.UnixNano(), s.seq, s.attempts+1)
+	os.Rename(s.path, filepath.Join(e.spool, name))
+}
+
+// spooled returns the spooled envelopes, oldest first.
+func (e *Exporter) spooled() []*spooledEnvelope {
+	if e.spool == "" {
+		return nil
+	}
+	paths, _ := filepath.Glob(filepath.Join(e.spool, "*.envelope"))
+	sort.Strings(paths)
+
+	var out []*spooledEnvelope
+	for _, path := range paths {
+		var nanos int64
+		s := &spooledEnvelope{path: path}
+		if _, err := fmt.Sscanf(filepath.Base(path), "%d-%d-%d.envelope", &nanos, &s.seq, &s.attempts); err != nil {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		s.size = info.Size()
+		s.created = time.Unix(0, nanos)
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,249 @@
+package sentryerr_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brad-jones/goerr/v2/sentryerr"
+	"github.com/stretchr/testify/assert"
+)
+
+type collector struct {
+	mu        sync.Mutex
+	fail      bool
+	auth      []string
+	envelopes [][]byte
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fail || r.URL.Path != "/sentry/api/42/envelope/" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	c.auth = append(c.auth, r.Header.Get("X-Sentry-Auth"))
+	c.envelopes = append(c.envelopes, body)
+}
+
+func (c *collector) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.envelopes)
+}
+
+func newServer(t *testing.T) (*collector, string) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	t.Cleanup(srv.Close)
+	return c, strings.Replace(srv.URL, "http://", "http://public@", 1) + "/sentry/42"
+}
+
+func TestParseDSN(t *testing.T) {
+	dsn, err := sentryerr.ParseDSN("https://public@sentry.example.com/prefix/42")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "https://sentry.example.com/prefix/api/42/envelope/", dsn.EnvelopeURL())
+	}
+	_, err = sentryerr.ParseDSN("https://sentry.example.com/42")
+	assert.EqualError(t, err, "dsn is missing the public key")
+	_, err = sentryerr.ParseDSN("https://public@sentry.example.com/")
+	assert.EqualError(t, err, "dsn is missing the project id")
+}
+
+func TestExporter(t *testing.T) {
+	c, dsn := newServer(t)
+	e, err := sentryerr.NewExporter(dsn, sentryerr.WithFlushInterval(0), sentryerr.WithRelease("app@1.0.0"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	id, err := e.Export(loadUser())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, c.count())
+	assert.NoError(t, e.Close(context.Background()))
+
+	if assert.Equal(t, 1, c.count()) {
+		assert.Contains(t, c.auth[0], "sentry_key=public")
+		lines := bytes.Split(c.envelopes[0], []byte("\n"))
+		if assert.Equal(t, 4, len(lines)) {
+			var header, item map[string]interface{}
+			var event sentryerr.Event
+			assert.NoError(t, json.Unmarshal(lines[0], &header))
+			assert.NoError(t, json.Unmarshal(lines[1], &item))
+			assert.NoError(t, json.Unmarshal(lines[2], &event))
+			assert.Equal(t, id, header["event_id"])
+			assert.Equal(t, "event", item["type"])
+			assert.Equal(t, float64(len(lines[2])), item["length"])
+			assert.Equal(t, id, event.EventID)
+			assert.Equal(t, "app@1.0.0", event.Release)
+		}
+	}
+
+	_, err = e.Export(loadUser())
+	assert.EqualError(t, err, "exporter is closed")
+}
+
+func TestExporterBatching(t *testing.T) {
+	c, dsn := newServer(t)
+	e, err := sentryerr.NewExporter(dsn, sentryerr.WithFlushInterval(0), sentryerr.WithBatchSize(2))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer e.Close(context.Background())
+	e.Export(loadUser())
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 0, c.count())
+	e.Export(loadUser())
+	assert.Eventually(t, func() bool { return c.count() == 2 }, time.Second, time.Millisecond)
+}
+
+func TestExporterSpool(t *testing.T) {
+	c, dsn := newServer(t)
+	dir := t.TempDir()
+	e, err := sentryerr.NewExporter(dsn, sentryerr.WithFlushInterval(0), sentryerr.WithSpool(dir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer e.Close(context.Background())
+
+	c.mu.Lock()
+	c.fail = true
+	c.mu.Unlock()
+	e.Export(loadUser())
+	e.Export(loadUser())
+	assert.Error(t, e.Flush(context.Background()))
+	spooled, _ := filepath.Glob(filepath.Join(dir, "*.envelope"))
+	if assert.Equal(t, 2, len(spooled)) {
+		info, err := os.Stat(spooled[0])
+		if assert.NoError(t, err) {
+			assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		}
+	}
+
+	c.mu.Lock()
+	c.fail = false
+	c.mu.Unlock()
+	assert.NoError(t, e.Flush(context.Background()))
+	assert.Equal(t, 2, c.count())
+	entries, _ := os.ReadDir(dir)
+	assert.Equal(t, 0, len(entries))
+}
+
+func TestExporterSpoolDir(t *testing.T) {
+	_, dsn := newServer(t)
+	dir := filepath.Join(t.TempDir(), "spool")
+	e, err := sentryerr.NewExporter(dsn, sentryerr.WithFlushInterval(0), sentryerr.WithSpool(dir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer e.Close(context.Background())
+	info, err := os.Stat(dir)
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+	}
+}
+
+// spoolFailed exports n events that fail to be sent, returning
+// the sizes of the files in the spool directory.
+func spoolFailed(t *testing.T, c *collector, dsn string, n int, opts ...sentryerr.Option) (*sentryerr.Exporter, []int64) {
+	dir := t.TempDir()
+	c.mu.Lock()
+	c.fail = true
+	c.mu.Unlock()
+	e, err := sentryerr.NewExporter(dsn, append([]sentryerr.Option{sentryerr.WithFlushInterval(0), sentryerr.WithSpool(dir)}, opts...)...)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { e.Close(context.Background()) })
+	for i := 0; i < n; i++ {
+		e.Export(loadUser())
+	}
+	assert.Error(t, e.Flush(context.Background()))
+	return e, spoolSizes(dir)
+}
+
+func spoolSizes(dir string) []int64 {
+	sizes := []int64{}
+	spooled, _ := filepath.Glob(filepath.Join(dir, "*.envelope"))
+	for _, path := range spooled {
+		if info, err := os.Stat(path); err == nil {
+			sizes = append(sizes, info.Size())
+		}
+	}
+	return sizes
+}
+
+func TestExporterMaxSpoolSize(t *testing.T) {
+	c, dsn := newServer(t)
+	_, sizes := spoolFailed(t, c, dsn, 1)
+	if !assert.Equal(t, 1, len(sizes)) {
+		return
+	}
+	_, sizes = spoolFailed(t, c, dsn, 3, sentryerr.WithMaxSpoolSize(sizes[0]*3/2))
+	assert.Equal(t, 1, len(sizes))
+	_, sizes = spoolFailed(t, c, dsn, 1, sentryerr.WithMaxSpoolSize(1))
+	assert.Equal(t, 0, len(sizes))
+}
+
+func TestExporterMaxSpoolAttempts(t *testing.T) {
+	c, dsn := newServer(t)
+	e, sizes := spoolFailed(t, c, dsn, 1, sentryerr.WithMaxSpoolAttempts(3))
+	assert.Equal(t, 1, len(sizes))
+	assert.Error(t, e.Flush(context.Background()))
+	assert.Error(t, e.Flush(context.Background()))
+
+	c.mu.Lock()
+	c.fail = false
+	c.mu.Unlock()
+	assert.NoError(t, e.Flush(context.Background()))
+	assert.Equal(t, 0, c.count())
+}
+
+func TestExporterMaxSpoolAge(t *testing.T) {
+	c, dsn := newServer(t)
+	e, sizes := spoolFailed(t, c, dsn, 1, sentryerr.WithMaxSpoolAge(time.Millisecond))
+	assert.Equal(t, 1, len(sizes))
+	time.Sleep(5 * time.Millisecond)
+
+	c.mu.Lock()
+	c.fail = false
+	c.mu.Unlock()
+	assert.NoError(t, e.Flush(context.Background()))
+	assert.Equal(t, 0, c.count())
+}
+
+func TestExporterCloseCancels(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+	dsn := strings.Replace(srv.URL, "http://", "http://public@", 1) + "/42"
+
+	e, err := sentryerr.NewExporter(dsn, sentryerr.WithFlushInterval(0), sentryerr.WithBatchSize(1))
+	if !assert.NoError(t, err) {
+		return
+	}
+	e.Export(loadUser())
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.Error(t, e.Close(ctx))
+	assert.True(t, time.Since(start) < time.Second)
+}
@@ -0,0 +1,146 @@
+package goerr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// LogValue implements the slog.LogValuer interface.
+//
+// The error is logged as a group containing the message,
+// any context values and the stack frames, see NewStackTrace.
+func (g *Error) LogValue() slog.Value {
+	return NewStackTrace(g).LogValue()
+}
+
+// LogValue implements the slog.LogValuer interface.
+func (s *StackTrace) LogValue() slog.Value {
+	return s.logValue(SlogStack)
+}
+
+func (s *StackTrace) logValue(verbosity SlogVerbosity) slog.Value {
+	if verbosity == SlogMessage {
+		return slog.StringValue(redactMessage(s.ErrorMsg))
+	}
+
+	attrs := []slog.Attr{slog.String("error-msg", redactMessage(s.ErrorMsg))}
+
+	if s.Code != OK {
+		attrs = append(attrs, slog.String("error-code", s.Code.String()))
+	}
+
+	if s.Panic != NotPanicked {
+		attrs = append(attrs, slog.String("panic", s.Panic.String()))
+	}
+
+	if s.ErrorCtx != nil {
+		safe := s.SafeCtx()
+		keys := sortedKeys(safe)
+		ctx := make([]slog.Attr, len(keys))
+		for i, k := range keys {
+			ctx[i] = slog.Any(k, safe[k])
+		}
+		attrs = append(attrs, slog.Attr{Key: "error-ctx", Value: slog.GroupValue(ctx...)})
+	}
+
+	if verbosity >= SlogStack && s.Stack != nil {
+		frames := make([]string, len(s.Stack))
+		for i, f := range s.Stack {
+			frames[i] = fmt.Sprintf("%s.%s:%s:%d", f.Package, f.Name, f.File, f.LineNumber)
+		}
+		attrs = append(attrs, slog.Any("stack", frames))
+	}
+
+	if s.Branches != nil {
+		branches := make([]slog.Attr, len(s.Branches))
+		for i, b := range s.Branches {
+			branches[i] = slog.Attr{Key: fmt.Sprint(i), Value: b.logValue(verbosity)}
+		}
+		attrs = append(attrs, slog.Attr{Key: "errors", Value: slog.GroupValue(branches...)})
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// SlogVerbosity controls how much of an error is logged by a SlogHandler.
+type SlogVerbosity int
+
+const (
+	// SlogMessage logs only the error message, the same as a plain error.
+	SlogMessage SlogVerbosity = iota
+
+	// SlogContext logs the error message and any context values.
+	SlogContext
+
+	// SlogStack logs the error message, context values & the stack frames.
+	SlogStack
+)
+
+// SlogHandler is a slog.Handler that wraps another handler, expanding any
+// attribute containing a goerr error into a group with the error message,
+// context values & stack frames, create new instances with NewSlogHandler.
+//
+// Unlike relying on the LogValue method of *Error alone, errors that are
+// wrapped by other error types are detected and expanded too.
+type SlogHandler struct {
+	next      slog.Handler
+	verbosity SlogVerbosity
+}
+
+// NewSlogHandler is the constructor for SlogHandler.
+func NewSlogHandler(next slog.Handler, verbosity SlogVerbosity) *SlogHandler {
+	return &SlogHandler{next: next, verbosity: verbosity}
+}
+
+// Enabled implements the slog.Handler interface.
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements the slog.Handler interface.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	expanded := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		expanded.AddAttrs(h.expand(a))
+		return true
+	})
+	return h.next.Handle(ctx, expanded)
+}
+
+// WithAttrs implements the slog.Handler interface.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		expanded[i] = h.expand(a)
+	}
+	return &SlogHandler{next: h.next.WithAttrs(expanded), verbosity: h.verbosity}
+}
+
+// WithGroup implements the slog.Handler interface.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	return &SlogHandler{next: h.next.WithGroup(name), verbosity: h.verbosity}
+}
+
+func (h *SlogHandler) expand(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		expanded := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			expanded[i] = h.expand(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(expanded...)}
+	case slog.KindAny, slog.KindLogValuer:
+		switch v := a.Value.Any().(type) {
+		case *StackTrace:
+			return slog.Attr{Key: a.Key, Value: v.logValue(h.verbosity)}
+		case error:
+			var e *Error
+			if As(v, &e) {
+				return slog.Attr{Key: a.Key, Value: NewStackTrace(v).logValue(h.verbosity)}
+			}
+		}
+	}
+	return a
+}
@@ -0,0 +1,56 @@
+package goerr_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func logJSON(t *testing.T, h func(slog.Handler) slog.Handler, args ...interface{}) map[string]interface{} {
+	buf := &bytes.Buffer{}
+	logger := slog.New(h(slog.NewJSONHandler(buf, nil)))
+	logger.Error("oops", args...)
+	var out map[string]interface{}
+	if !assert.NoError(t, json.Unmarshal(buf.Bytes(), &out)) {
+		t.FailNow()
+	}
+	return out
+}
+
+func TestErrorLogValue(t *testing.T) {
+	err := goerr.WrapWith(goerr.New("abc"), goerr.With("user_id", 123))
+	out := logJSON(t, func(h slog.Handler) slog.Handler { return h }, "err", err)
+	logged := out["err"].(map[string]interface{})
+	assert.Equal(t, "abc", logged["error-msg"])
+	assert.Equal(t, map[string]interface{}{"user_id": float64(123)}, logged["error-ctx"])
+	assert.Equal(t, 1, len(logged["stack"].([]interface{})))
+}
+
+func TestSlogHandler(t *testing.T) {
+	err := fmt.Errorf("outer: %w", goerr.WrapWith(goerr.New("abc"), goerr.With("user_id", 123)))
+
+	out := logJSON(t, func(h slog.Handler) slog.Handler {
+		return goerr.NewSlogHandler(h, goerr.SlogStack)
+	}, slog.Group("req", "err", err))
+	logged := out["req"].(map[string]interface{})["err"].(map[string]interface{})
+	assert.Equal(t, "outer: abc", logged["error-msg"])
+	assert.Equal(t, 1, len(logged["stack"].([]interface{})))
+
+	out = logJSON(t, func(h slog.Handler) slog.Handler {
+		return goerr.NewSlogHandler(h, goerr.SlogContext)
+	}, "err", err)
+	logged = out["err"].(map[string]interface{})
+	assert.Equal(t, "outer: abc", logged["error-msg"])
+	assert.Equal(t, map[string]interface{}{"user_id": float64(123)}, logged["error-ctx"])
+	assert.Nil(t, logged["stack"])
+
+	out = logJSON(t, func(h slog.Handler) slog.Handler {
+		return goerr.NewSlogHandler(h, goerr.SlogMessage).WithAttrs([]slog.Attr{slog.Any("err", err)})
+	})
+	assert.Equal(t, "outer: abc", out["err"])
+}
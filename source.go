@@ -0,0 +1,158 @@
+package goerr
+
+import (
+	"bytes"
+	"container/list"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SourceProvider provides the source code of the files referenced by
+// stack frames, see SetSourceProvider.
+type SourceProvider interface {
+	ReadFile(path string) ([]byte, error)
+}
+
+// FileSystemSource is a SourceProvider that reads source code from the
+// local filesystem, this is the default SourceProvider.
+type FileSystemSource struct{}
+
+// ReadFile implements the SourceProvider interface.
+func (FileSystemSource) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// NoSource is a SourceProvider that never provides any source code,
+// useful when it is known the source will not be available at runtime.
+type NoSource struct{}
+
+// ReadFile implements the SourceProvider interface.
+func (NoSource) ReadFile(path string) ([]byte, error) {
+	return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+}
+
+// FSSource is a SourceProvider that reads source code from a fs.FS,
+// create new instances with NewFSSource.
+//
+// This works with an embed.FS, allowing the source code to be
+// compiled into the binary for use in containers and the like.
+type FSSource struct {
+	fsys fs.FS
+	root string
+}
+
+// NewFSSource is the constructor for FSSource.
+//
+// The absolute file paths of stack frames are made relative to root before
+// being read from fsys. Usually root is the directory containing the go.mod
+// file of the program at build time.
+//
+// For example:
+//
+//	//go:embed *.go
+//	var src embed.FS
+//
+//	goerr.SetSourceProvider(goerr.NewFSSource(src, "/build/src/myapp"))
+func NewFSSource(fsys fs.FS, root string) *FSSource {
+	return &FSSource{fsys: fsys, root: filepath.ToSlash(root)}
+}
+
+// ReadFile implements the SourceProvider interface.
+//
+// Paths that are not within root do not exist.
+func (s *FSSource) ReadFile(path string) ([]byte, error) {
+	prefix := strings.TrimSuffix(s.root, "/") + "/"
+	if !strings.HasPrefix(filepath.ToSlash(path), prefix) {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+	return fs.ReadFile(s.fsys, strings.TrimPrefix(filepath.ToSlash(path), prefix))
+}
+
+// SetSourceProvider sets the SourceProvider used to lookup the source code
+// of stack frames and resets the source cache.
+func SetSourceProvider(p SourceProvider) {
+	sources.mu.Lock()
+	defer sources.mu.Unlock()
+	sources.provider = p
+	sources.reset()
+}
+
+// SetSourceCacheSize sets the maximum number of files that will be held in
+// the source cache, the least recently used files are evicted first.
+// The default size is 64, a size of 0 disables caching.
+func SetSourceCacheSize(size int) {
+	sources.mu.Lock()
+	defer sources.mu.Unlock()
+	sources.size = size
+	sources.reset()
+}
+
+var sources = &sourceCache{
+	provider: FileSystemSource{},
+	size:     64,
+	entries:  map[string]*list.Element{},
+	lru:      list.New(),
+}
+
+// sourceCache is a concurrent safe, bounded, least recently used
+// cache of the lines of each source file read by a SourceProvider.
+//
+// Files are read without holding the lock, concurrent lookups of
+// the same file wait for the first read rather than reading it again.
+type sourceCache struct {
+	mu       sync.Mutex
+	provider SourceProvider
+	size     int
+	entries  map[string]*list.Element
+	lru      *list.List
+}
+
+type sourceEntry struct {
+	path  string
+	ready chan struct{}
+	lines []string
+	err   error
+}
+
+func (c *sourceCache) reset() {
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+}
+
+func (c *sourceCache) lines(path string) ([]string, error) {
+	c.mu.Lock()
+	if el, ok := c.entries[path]; ok {
+		c.lru.MoveToFront(el)
+		c.mu.Unlock()
+		entry := el.Value.(*sourceEntry)
+		<-entry.ready
+		return entry.lines, entry.err
+	}
+
+	entry := &sourceEntry{path: path, ready: make(chan struct{})}
+	provider := c.provider
+	if c.size > 0 {
+		c.entries[path] = c.lru.PushFront(entry)
+		for c.lru.Len() > c.size {
+			oldest := c.lru.Back()
+			c.lru.Remove(oldest)
+			delete(c.entries, oldest.Value.(*sourceEntry).path)
+		}
+	}
+	c.mu.Unlock()
+
+	src, err := provider.ReadFile(path)
+	if err != nil {
+		entry.err = err
+	} else {
+		for _, line := range bytes.Split(bytes.TrimSuffix(src, []byte("\n")), []byte("\n")) {
+			entry.lines = append(entry.lines, string(bytes.TrimRight(line, "\r")))
+		}
+	}
+	close(entry.ready)
+
+	return entry.lines, entry.err
+}
@@ -0,0 +1,168 @@
+package goerr_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingSource struct {
+	reads int
+}
+
+func (s *countingSource) ReadFile(path string) ([]byte, error) {
+	s.reads++
+	return []byte("line 1\n\tline 2\nline 3\nline 4\n"), nil
+}
+
+func TestSourceCache(t *testing.T) {
+	src := &countingSource{}
+	goerr.SetSourceProvider(src)
+	defer goerr.SetSourceProvider(goerr.FileSystemSource{})
+
+	frame := &goerr.StackFrame{File: "/a.go", LineNumber: 2}
+	for i := 0; i < 3; i++ {
+		line, err := frame.SourceLine()
+		if assert.NoError(t, err) {
+			assert.Equal(t, "line 2", line)
+		}
+	}
+	assert.Equal(t, 1, src.reads)
+
+	frame = &goerr.StackFrame{File: "/a.go", LineNumber: 5}
+	line, err := frame.SourceLine()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "???", line)
+	}
+}
+
+func TestSourceCacheSize(t *testing.T) {
+	src := &countingSource{}
+	goerr.SetSourceProvider(src)
+	goerr.SetSourceCacheSize(1)
+	defer goerr.SetSourceCacheSize(64)
+	defer goerr.SetSourceProvider(goerr.FileSystemSource{})
+
+	for _, file := range []string{"/a.go", "/b.go", "/a.go"} {
+		_, err := (&goerr.StackFrame{File: file, LineNumber: 1}).SourceLine()
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 3, src.reads)
+}
+
+func TestSourceLines(t *testing.T) {
+	goerr.SetSourceProvider(&countingSource{})
+	defer goerr.SetSourceProvider(goerr.FileSystemSource{})
+
+	lines, start, err := (&goerr.StackFrame{File: "/a.go", LineNumber: 1}).SourceLines(1)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, start)
+		assert.Equal(t, []string{"line 1", "\tline 2"}, lines)
+	}
+
+	lines, start, err = (&goerr.StackFrame{File: "/a.go", LineNumber: 3}).SourceLines(5)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, start)
+		assert.Equal(t, []string{"line 1", "\tline 2", "line 3", "line 4"}, lines)
+	}
+
+	_, _, err = (&goerr.StackFrame{File: "/a.go", LineNumber: 9}).SourceLines(1)
+	assert.Error(t, err)
+
+	lines, _, _ = (&goerr.StackFrame{File: "/a.go", LineNumber: 1}).SourceLines(0)
+	lines[0] = "changed"
+	line, _ := (&goerr.StackFrame{File: "/a.go", LineNumber: 1}).SourceLine()
+	assert.Equal(t, "line 1", line)
+}
+
+type blockingSource struct {
+	mu      sync.Mutex
+	reads   int
+	release chan struct{}
+}
+
+func (s *blockingSource) ReadFile(path string) ([]byte, error) {
+	s.mu.Lock()
+	s.reads++
+	s.mu.Unlock()
+	if path == "/slow.go" {
+		<-s.release
+	}
+	return []byte("line 1\n"), nil
+}
+
+func TestSourceCacheConcurrent(t *testing.T) {
+	src := &blockingSource{release: make(chan struct{})}
+	goerr.SetSourceProvider(src)
+	defer goerr.SetSourceProvider(goerr.FileSystemSource{})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			line, err := (&goerr.StackFrame{File: "/slow.go", LineNumber: 1}).SourceLine()
+			assert.NoError(t, err)
+			assert.Equal(t, "line 1", line)
+		}()
+	}
+
+	// A slow read must not block the lookup of any other file
+	line, err := (&goerr.StackFrame{File: "/fast.go", LineNumber: 1}).SourceLine()
+	assert.NoError(t, err)
+	assert.Equal(t, "line 1", line)
+
+	close(src.release)
+	wg.Wait()
+	assert.Equal(t, 2, src.reads)
+}
+
+func TestNoSource(t *testing.T) {
+	goerr.SetSourceProvider(goerr.NoSource{})
+	defer goerr.SetSourceProvider(goerr.FileSystemSource{})
+
+	pc, _, _, _ := runtime.Caller(0)
+	_, err := goerr.NewStackFrame(pc).SourceLine()
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestFSSource(t *testing.T) {
+	root, err := os.Getwd()
+	if !assert.NoError(t, err) {
+		return
+	}
+	goerr.SetSourceProvider(goerr.NewFSSource(fstest.MapFS{
+		"pkg/main.go": &fstest.MapFile{Data: []byte("package main\n\nfunc main() {}\n")},
+	}, root))
+	defer goerr.SetSourceProvider(goerr.FileSystemSource{})
+
+	line, err := (&goerr.StackFrame{File: filepath.Join(root, "pkg", "main.go"), LineNumber: 3}).SourceLine()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "func main() {}", line)
+	}
+
+	_, err = (&goerr.StackFrame{File: root + "pkg/main.go", LineNumber: 3}).SourceLine()
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestTextRendererSourceContext(t *testing.T) {
+	goerr.SetSourceProvider(&countingSource{})
+	defer goerr.SetSourceProvider(goerr.FileSystemSource{})
+
+	st := &goerr.StackTrace{
+		ErrorMsg: "abc",
+		Stack:    []*goerr.StackFrame{{Package: "main", Name: "main", File: "/a.go", LineNumber: 2}},
+	}
+	buf := &bytes.Buffer{}
+	if assert.NoError(t, (goerr.TextRenderer{SourceContext: 1}).Render(buf, st)) {
+		assert.Equal(t, "abc\n\nmain.main:/a.go:2\n\t  1 | line 1\n\t> 2 | \tline 2\n\t  3 | line 3\n\n", buf.String())
+	}
+}
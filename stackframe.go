@@ -26,11 +26,8 @@ package goerr
 */
 
 import (
-	"bufio"
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"os"
 	"runtime"
 	"strings"
 )
@@ -47,6 +44,15 @@ type StackFrame struct {
 	Package string
 	// The underlying ProgramCounter
 	ProgramCounter uintptr
+	// Wrapped is true when this frame is the site of an explicit Trace,
+	// Wrap or Check call as opposed to a frame from a captured full stack.
+	Wrapped bool
+	// Context is any structured context that was attached at this frame
+	Context map[string]interface{}
+
+	// source is the line of code of a frame that was deserialized
+	// from a trace, see UnmarshalJSON
+	source string
 }
 
 // NewStackFrame populates a stack frame object from the program counter.
@@ -55,11 +61,21 @@ func NewStackFrame(pc uintptr) (frame *StackFrame) {
 	if frame.Func() == nil {
 		return
 	}
-	frame.Package, frame.Name = packageAndName(frame.Func())
+	frame.Package, frame.Name = packageAndName(frame.Func().Name())
 	frame.File, frame.LineNumber = frame.Func().FileLine(pc)
 	return
 }
 
+func newStackFrameFromRuntime(f runtime.Frame) *StackFrame {
+	frame := &StackFrame{
+		File:           f.File,
+		LineNumber:     f.Line,
+		ProgramCounter: f.PC,
+	}
+	frame.Package, frame.Name = packageAndName(f.Function)
+	return frame
+}
+
 // Func returns the function that contained this frame.
 func (frame *StackFrame) Func() *runtime.Func {
 	if frame.ProgramCounter == 0 {
@@ -75,12 +91,22 @@ func (frame *StackFrame) String() string {
 		frame.Package, frame.Name, frame.File, frame.LineNumber,
 	)
 
-	source, err := frame.SourceLine()
-	if err != nil {
-		return str
+	if source, err := frame.SourceLine(); err == nil {
+		str = str + fmt.Sprintf("\t%s\n", source)
+	}
+
+	if len(frame.Context) > 0 {
+		str = str + fmt.Sprintf("\tctx: %s\n", formatPairs(frame.Context))
 	}
 
-	return str + fmt.Sprintf("\t%s\n", source)
+	return str
+}
+
+// SafeCtx returns the context values of the frame as they are output in
+// the trace, see StackTrace.SafeCtx
+func (frame *StackFrame) SafeCtx() map[string]interface{} {
+	ctx, _ := safeCtx(frame.Context)
+	return ctx
 }
 
 // MarshalJSON implements the Marshaler interface
@@ -93,6 +119,14 @@ func (frame *StackFrame) MarshalJSON() ([]byte, error) {
 		"lineno":  frame.LineNumber,
 	}
 
+	if frame.Wrapped {
+		data["wrapped"] = true
+	}
+
+	if len(frame.Context) > 0 {
+		data["ctx"] = frame.SafeCtx()
+	}
+
 	if source, err := frame.SourceLine(); err == nil {
 		data["src"] = source
 	}
@@ -100,36 +134,89 @@ func (frame *StackFrame) MarshalJSON() ([]byte, error) {
 	return json.Marshal(data)
 }
 
+// UnmarshalJSON implements the Unmarshaler interface
+// see https://golang.org/pkg/encoding/json/#Unmarshaler
+//
+// The line of code of the frame, if included, is retained
+// and will be returned by SourceLine.
+func (frame *StackFrame) UnmarshalJSON(b []byte) error {
+	var data struct {
+		Package    string                 `json:"package"`
+		Name       string                 `json:"method"`
+		File       string                 `json:"file"`
+		LineNumber int                    `json:"lineno"`
+		Wrapped    bool                   `json:"wrapped"`
+		Context    map[string]interface{} `json:"ctx"`
+		Source     string                 `json:"src"`
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*frame = StackFrame{
+		Package:    data.Package,
+		Name:       data.Name,
+		File:       data.File,
+		LineNumber: data.LineNumber,
+		Wrapped:    data.Wrapped,
+		Context:    data.Context,
+		source:     data.Source,
+	}
+	return nil
+}
+
 // SourceLine gets the line of code (from File and Line)
 // of the original source if possible.
+//
+// Source files are read with the configured SourceProvider & cached,
+// see SetSourceProvider & SetSourceCacheSize.
 func (frame *StackFrame) SourceLine() (string, error) {
+	if frame.source != "" {
+		return frame.source, nil
+	}
+
 	if frame.LineNumber <= 0 {
 		return "???", nil
 	}
 
-	file, err := os.Open(frame.File)
+	lines, err := sources.lines(frame.File)
 	if err != nil {
 		return "", err
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	currentLine := 1
-	for scanner.Scan() {
-		if currentLine == frame.LineNumber {
-			return string(bytes.Trim(scanner.Bytes(), " \t")), nil
-		}
-		currentLine++
+	if frame.LineNumber > len(lines) {
+		return "???", nil
 	}
-	if err := scanner.Err(); err != nil {
-		return "", err
+
+	return strings.Trim(lines[frame.LineNumber-1], " \t"), nil
+}
+
+// SourceLines gets the line of code (from File and Line) along with up to n
+// lines of surrounding context either side of it, the line number of the
+// first returned line is also returned. Unlike SourceLine the indentation
+// of each line is preserved.
+func (frame *StackFrame) SourceLines(n int) ([]string, int, error) {
+	lines, err := sources.lines(frame.File)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if frame.LineNumber <= 0 || frame.LineNumber > len(lines) {
+		return nil, 0, fmt.Errorf("line %d not found in %s", frame.LineNumber, frame.File)
+	}
+
+	start := frame.LineNumber - n
+	if start < 1 {
+		start = 1
+	}
+	end := frame.LineNumber + n
+	if end > len(lines) {
+		end = len(lines)
 	}
 
-	return "???", nil
+	return append([]string{}, lines[start-1:end]...), start, nil
 }
 
-func packageAndName(fn *runtime.Func) (string, string) {
-	name := fn.Name()
+func packageAndName(name string) (string, string) {
 	pkg := ""
 
 	// The name includes the path name to the package, which is unnecessary
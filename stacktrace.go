@@ -2,17 +2,28 @@ package goerr
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 )
 
+// TraceSchemaVersion is the version of the JSON schema output by
+// StackTrace.MarshalJSON, it is incremented on incompatible changes.
+const TraceSchemaVersion = 1
+
 // StackTrace is an object that represents a stack trace for a given error,
 // create new instances with NewStackTrace.
 type StackTrace struct {
-	Error    error
-	Cause    error
-	ErrorMsg string
-	ErrorCtx map[string]interface{}
-	Stack    []*StackFrame
+	Error     error
+	Cause     error
+	ErrorMsg  string
+	ErrorCtx  map[string]interface{}
+	Code      Code
+	Panic     PanicKind
+	Templates []*MessageTemplate
+	Stack     []*StackFrame
+	Branches  []*StackTrace
+
+	fingerprint string
 }
 
 // NewStackTrace is the constructor for StackTrace
@@ -20,18 +31,36 @@ func NewStackTrace(err error) *StackTrace {
 	st := &StackTrace{
 		Error:    err,
 		Cause:    Cause(err),
-		ErrorMsg: err.Error(),
+		ErrorMsg: redactTraceMessage(err.Error()),
+		Code:     codeOf(err),
+		Panic:    panicOf(err),
 	}
 
+	// Keep the raw message templates such that the message can be localized
+	st.Templates = templatesOf(err)
+
 	// Assign any additional context values
-	st.ErrorCtx = marshalError(st.Cause)
+	st.ErrorCtx = redactTraceCtx(mergeCtx(err, marshalError(st.Cause)))
+
+	// Generate a trace for each branch of a multi error
+	if multi, ok := st.Cause.(interface{ Unwrap() []error }); ok {
+		for _, branch := range multi.Unwrap() {
+			st.Branches = append(st.Branches, NewStackTrace(branch))
+		}
+	}
 
 	// Grab all the frames from each error in the error chain
 	frames := []*StackFrame{}
-	var e *Error
-	if As(err, &e) {
+	var stack []*StackFrame
+	if e := chainError(err); e != nil {
 		for {
-			frames = append(frames, e.Frame())
+			frame := e.Frame()
+			frame.Wrapped = true
+			frame.Context = redactTraceCtx(frame.Context)
+			frames = append(frames, frame)
+			if e.stack != nil {
+				stack = e.Stack()
+			}
 			unWrapped := Unwrap(e)
 			if unWrapped == nil {
 				break
@@ -55,62 +84,214 @@ func NewStackTrace(err error) *StackTrace {
 			opp := len(frames) - 1 - i
 			frames[i], frames[opp] = frames[opp], frames[i]
 		}
+		if stack != nil {
+			frames = mergeFrames(stack, frames)
+		}
 		st.Stack = frames
 	}
 
+	// Frames from a deserialized trace are deeper than any local frames
+	if remote, ok := st.Cause.(*RemoteError); ok && len(remote.frames) > 0 {
+		st.Stack = append(append([]*StackFrame{}, remote.frames...), st.Stack...)
+	}
+	if remote, ok := st.Cause.(*RemoteError); ok {
+		st.Templates = append(st.Templates, remote.templates...)
+	}
+
 	return st
 }
 
-// String implements the Stringer interface
-func (s *StackTrace) String() string {
-	st := s.ErrorMsg + "\n\n"
+// chainError returns the first *Error in err's chain. Unlike As it only
+// follows errors that wrap a single error, the frames of each branch of a
+// *MultiError belong to that branch's own trace.
+func chainError(err error) *Error {
+	for err != nil {
+		if e, ok := err.(*Error); ok {
+			return e
+		}
+		err = Unwrap(err)
+	}
+	return nil
+}
 
-	if s.ErrorCtx != nil {
-		ctx, err := json.MarshalIndent(s.ErrorCtx, "", "    ")
-		if err != nil {
-			panic(err)
+// mergeFrames replaces the frames of a full stack with the wrap point frames
+// at the same function, file & line. A wrap point on a different line of a
+// function on the stack (say the error was wrapped after the call returned)
+// is inserted before that frame, unless an exact match follows, such as with
+// recursion. Any wrap points that could not be found on the stack (say the
+// error crossed a goroutine) are appended to the end.
+func mergeFrames(stack, wraps []*StackFrame) []*StackFrame {
+	merged := []*StackFrame{}
+	for i, f := range stack {
+		if len(wraps) > 0 && wraps[0].Package == f.Package && wraps[0].Name == f.Name {
+			if sameFrame(wraps[0], f) {
+				merged = append(merged, wraps[0])
+				wraps = wraps[1:]
+				continue
+			}
+			if !containsFrame(stack[i+1:], wraps[0]) {
+				merged = append(merged, wraps[0])
+				wraps = wraps[1:]
+			}
 		}
-		st = st + string(ctx) + "\n\n"
+		merged = append(merged, f)
 	}
+	return append(merged, wraps...)
+}
 
-	if s.Stack != nil {
-		for _, f := range s.Stack {
-			st = st + f.String()
+func sameFrame(a, b *StackFrame) bool {
+	return a.Package == b.Package && a.Name == b.Name && a.File == b.File && a.LineNumber == b.LineNumber
+}
+
+func containsFrame(frames []*StackFrame, frame *StackFrame) bool {
+	for _, f := range frames {
+		if sameFrame(f, frame) {
+			return true
 		}
-		st = st + "\n"
 	}
+	return false
+}
 
-	return st
+// String implements the Stringer interface, see TextRenderer
+func (s *StackTrace) String() string {
+	sb := &strings.Builder{}
+	(TextRenderer{}).Render(sb, s)
+	return sb.String()
+}
+
+// SafeCtx returns the context values of the error as they are output in
+// the trace, with any sensitive values redacted, large values truncated
+// & values that can not be encoded as JSON replaced. see MaxCtxKeys
+func (s *StackTrace) SafeCtx() map[string]interface{} {
+	ctx, _ := safeCtx(s.ErrorCtx)
+	return ctx
 }
 
 // MarshalJSON implements the Marshaler interface
 // see https://golang.org/pkg/encoding/json/#Marshaler
 func (s *StackTrace) MarshalJSON() ([]byte, error) {
 	data := map[string]interface{}{
-		"error-msg": s.ErrorMsg,
+		"schema":      TraceSchemaVersion,
+		"error-msg":   redactMessage(s.ErrorMsg),
+		"fingerprint": s.Fingerprint(),
 	}
 
 	if s.ErrorCtx != nil {
-		data["error-ctx"] = s.ErrorCtx
+		data["error-ctx"] = s.SafeCtx()
+	}
+
+	if s.Code != OK {
+		data["error-code"] = s.Code
+	}
+
+	if s.Panic != NotPanicked {
+		data["panic"] = s.Panic
+	}
+
+	if s.Templates != nil {
+		templates := make([]*MessageTemplate, len(s.Templates))
+		for i, t := range s.Templates {
+			params, _ := safeCtx(t.Params)
+			templates[i] = &MessageTemplate{Template: t.Template, Params: params}
+		}
+		data["error-tmpl"] = templates
 	}
 
 	if s.Stack != nil {
 		data["stack"] = s.Stack
 	}
 
+	if s.Branches != nil {
+		data["errors"] = s.Branches
+	}
+
 	return json.Marshal(data)
 }
 
-func marshalError(err error) map[string]interface{} {
-	if j, jerr := json.Marshal(err); jerr == nil {
-		jS := string(j)
-		if strings.HasPrefix(jS, "{") && jS != "{}" {
-			var out map[string]interface{}
-			if err := json.Unmarshal(j, &out); err != nil {
-				return nil
+// UnmarshalJSON implements the Unmarshaler interface
+// see https://golang.org/pkg/encoding/json/#Unmarshaler
+//
+// As the original error can not be recovered both Error & Cause are set
+// to a *RemoteError that preserves the message, context and frames.
+func (s *StackTrace) UnmarshalJSON(b []byte) error {
+	var data struct {
+		Schema      int                    `json:"schema"`
+		ErrorMsg    string                 `json:"error-msg"`
+		ErrorCtx    map[string]interface{} `json:"error-ctx"`
+		Code        Code                   `json:"error-code"`
+		Panic       PanicKind              `json:"panic"`
+		Templates   []*MessageTemplate     `json:"error-tmpl"`
+		Fingerprint string                 `json:"fingerprint"`
+		Stack       []*StackFrame          `json:"stack"`
+		Branches    []*StackTrace          `json:"errors"`
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	if data.Schema > TraceSchemaVersion {
+		return fmt.Errorf("unsupported trace schema version %d, expected <= %d", data.Schema, TraceSchemaVersion)
+	}
+
+	remote := &RemoteError{
+		message:     data.ErrorMsg,
+		code:        data.Code,
+		panic:       data.Panic,
+		templates:   data.Templates,
+		fingerprint: data.Fingerprint,
+		ctx:         data.ErrorCtx,
+		frames:      data.Stack,
+	}
+	for _, b := range data.Branches {
+		remote.errs = append(remote.errs, b.Error)
+	}
+
+	*s = StackTrace{
+		Error:     remote,
+		Cause:     remote,
+		ErrorMsg:  data.ErrorMsg,
+		ErrorCtx:  data.ErrorCtx,
+		Code:      data.Code,
+		Panic:     data.Panic,
+		Templates: data.Templates,
+		Stack:     data.Stack,
+		Branches:  data.Branches,
+
+		fingerprint: data.Fingerprint,
+	}
+	return nil
+}
+
+// isFull reports if the stack contains both wrap points and frames from a
+// full stack capture, in which case the wrap points will be highlighted.
+func (s *StackTrace) isFull() bool {
+	wrapped, unwrapped := false, false
+	for _, f := range s.Stack {
+		if f.Wrapped {
+			wrapped = true
+		} else {
+			unwrapped = true
+		}
+	}
+	return wrapped && unwrapped
+}
+
+// mergeCtx merges the fields of every error in the chain, from the outer most
+// error to the cause, such that context set closer to the cause takes precedence.
+func mergeCtx(err error, causeCtx map[string]interface{}) map[string]interface{} {
+	var out map[string]interface{}
+	set := func(ctx map[string]interface{}) {
+		for k, v := range ctx {
+			if out == nil {
+				out = map[string]interface{}{}
 			}
-			return out
+			out[k] = v
 		}
 	}
-	return nil
+	for e := err; e != nil; e = Unwrap(e) {
+		if g, ok := e.(*Error); ok {
+			set(g.Fields())
+		}
+	}
+	set(causeCtx)
+	return out
 }
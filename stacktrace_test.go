@@ -1,6 +1,7 @@
 package goerr_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -44,3 +45,89 @@ func TestStackTraceWithCtx(t *testing.T) {
 		"Bar": "abc",
 	}, st.ErrorCtx)
 }
+
+func fullStackCrash1() error {
+	return goerr.Wrap(fullStackCrash2())
+}
+
+func fullStackCrash2() error {
+	return fullStackCrash3()
+}
+
+func fullStackCrash3() error {
+	return goerr.WrapWith(goerr.New("abc"), goerr.FullStack())
+}
+
+func TestStackTraceFullStack(t *testing.T) {
+	st := goerr.NewStackTrace(fullStackCrash1())
+	names := []string{}
+	wrapped := []string{}
+	for _, f := range st.Stack {
+		names = append(names, f.Name)
+		if f.Wrapped {
+			wrapped = append(wrapped, f.Name)
+		}
+	}
+	assert.Equal(t, []string{"fullStackCrash3", "fullStackCrash2", "fullStackCrash1", "TestStackTraceFullStack"}, names[:4])
+	assert.Equal(t, []string{"fullStackCrash3", "fullStackCrash1"}, wrapped)
+	assert.Contains(t, st.String(), "* github.com/brad-jones/goerr/v2_test.fullStackCrash3:")
+	assert.Contains(t, st.String(), "  github.com/brad-jones/goerr/v2_test.fullStackCrash2:")
+}
+
+func fullStackCrash4() error {
+	err := fullStackCrash3()
+	return goerr.Wrap(err)
+}
+
+func fullStackRecurse(n int) error {
+	if n == 0 {
+		return goerr.WrapWith(goerr.New("abc"), goerr.FullStack())
+	}
+	if n == 2 {
+		return goerr.Wrap(fullStackRecurse(n - 1))
+	}
+	return fullStackRecurse(n - 1)
+}
+
+func TestStackTraceFullStackMergeLines(t *testing.T) {
+	st := goerr.NewStackTrace(fullStackCrash4())
+	if assert.True(t, len(st.Stack) > 3) {
+		assert.Equal(t, "fullStackCrash3", st.Stack[0].Name)
+		assert.True(t, st.Stack[0].Wrapped)
+		assert.Equal(t, "fullStackCrash4", st.Stack[1].Name)
+		assert.True(t, st.Stack[1].Wrapped)
+		assert.Equal(t, "fullStackCrash4", st.Stack[2].Name)
+		assert.False(t, st.Stack[2].Wrapped)
+		assert.Equal(t, st.Stack[2].LineNumber+1, st.Stack[1].LineNumber)
+	}
+
+	st = goerr.NewStackTrace(fullStackRecurse(3))
+	wrapped := []bool{}
+	for _, f := range st.Stack {
+		if f.Name == "fullStackRecurse" {
+			wrapped = append(wrapped, f.Wrapped)
+		}
+	}
+	assert.Equal(t, []bool{true, false, true, false}, wrapped)
+}
+
+func TestStackTraceWithFields(t *testing.T) {
+	err := goerr.WrapWith(&fooError{Bar: "abc"}, goerr.With("user_id", 123), goerr.With("Bar", "ignored"))
+	err = goerr.WrapWith(err, goerr.With("tenant", "acme"), goerr.With("user_id", 456))
+	st := goerr.NewStackTrace(err)
+	assert.Equal(t, map[string]interface{}{
+		"Bar":     "abc",
+		"tenant":  "acme",
+		"user_id": 123,
+	}, st.ErrorCtx)
+	if assert.Equal(t, 2, len(st.Stack)) {
+		assert.Equal(t, map[string]interface{}{"user_id": 123, "Bar": "ignored"}, st.Stack[0].Context)
+		assert.Equal(t, map[string]interface{}{"tenant": "acme", "user_id": 456}, st.Stack[1].Context)
+	}
+	assert.Contains(t, st.String(), "\tctx: tenant=acme user_id=456\n")
+
+	j, jerr := json.Marshal(st)
+	if assert.NoError(t, jerr) {
+		assert.Contains(t, string(j), `"ctx":{"tenant":"acme","user_id":456}`)
+	}
+}
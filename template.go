@@ -0,0 +1,78 @@
+package goerr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Params are the named parameters of a message template, see Template.
+type Params map[string]interface{}
+
+// MessageTemplate is the raw template & params of a message,
+// as recorded in a StackTrace.
+type MessageTemplate struct {
+	Template string `json:"tmpl"`
+	Params   Params `json:"params,omitempty"`
+}
+
+// Template sets the human friendly message of the error from a template,
+// it is an alternative to Msg that allows the message to be localized.
+//
+// Parameters are referenced by name in braces, the template its self is
+// used as the message id when looking up translations, see Localize.
+//
+// For example:
+//
+//	goerr.WrapWith(err, goerr.Template("user {id} not found", goerr.Params{"id": id}))
+func Template(template string, params Params) Option {
+	return func(e *Error) {
+		e.template = &MessageTemplate{Template: template, Params: params}
+		e.message = RenderTemplate(template, params)
+	}
+}
+
+// Template returns the message template set on this error, if any.
+func (g *Error) Template() *MessageTemplate {
+	return g.template
+}
+
+// RenderTemplate replaces each {name} in template with the matching
+// parameter, placeholders without a parameter are left as is.
+func RenderTemplate(template string, params Params) string {
+	sb := &strings.Builder{}
+	for {
+		start := strings.IndexByte(template, '{')
+		if start == -1 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end == -1 {
+			break
+		}
+		end += start
+		sb.WriteString(template[:start])
+		if v, ok := params[template[start+1:end]]; ok {
+			sb.WriteString(fmt.Sprint(v))
+		} else {
+			sb.WriteString(template[start : end+1])
+		}
+		template = template[end+1:]
+	}
+	sb.WriteString(template)
+	return sb.String()
+}
+
+// templatesOf returns the message templates of each error in err's chain,
+// from the outer most error to the cause, with the params redacted.
+func templatesOf(err error) []*MessageTemplate {
+	var out []*MessageTemplate
+	for e := err; e != nil; e = Unwrap(e) {
+		if g, ok := e.(*Error); ok && g.template != nil {
+			out = append(out, &MessageTemplate{
+				Template: g.template.Template,
+				Params:   redactTraceCtx(g.template.Params),
+			})
+		}
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+package goerr_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/brad-jones/goerr/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	assert.Equal(t, "user 123 not found in {db}",
+		goerr.RenderTemplate("user {id} not found in {db}", goerr.Params{"id": 123}),
+	)
+	assert.Equal(t, "unclosed {id", goerr.RenderTemplate("unclosed {id", goerr.Params{"id": 1}))
+}
+
+func TestTemplate(t *testing.T) {
+	e := goerr.WrapWith(goerr.New("abc"), goerr.Template("user {id} not found", goerr.Params{"id": "123"}))
+	assert.EqualError(t, e, "user 123 not found: abc")
+	assert.Equal(t, "user {id} not found", e.Template().Template)
+	assert.Equal(t, goerr.Params{"id": "123"}, e.Template().Params)
+	assert.Nil(t, goerr.Wrap(e).Template())
+}
+
+func TestStackTraceTemplates(t *testing.T) {
+	e := goerr.WrapWith(
+		goerr.WrapWith(goerr.New("abc"), goerr.Template("user {id} not found", goerr.Params{"id": "123", "token": "s3cr3t"})),
+		goerr.Template("loading {page}", goerr.Params{"page": "profile"}),
+	)
+	st := goerr.NewStackTrace(e)
+	if assert.Equal(t, 2, len(st.Templates)) {
+		assert.Equal(t, "loading {page}", st.Templates[0].Template)
+		assert.Equal(t, "user {id} not found", st.Templates[1].Template)
+		assert.Equal(t, goerr.Redacted, st.Templates[1].Params["token"])
+	}
+
+	j, err := json.Marshal(st)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(j), `"error-tmpl":[{"tmpl":"loading {page}","params":{"page":"profile"}}`)
+		parsed, err := goerr.ParseTrace(j)
+		if assert.NoError(t, err) {
+			assert.Equal(t, st.Templates, parsed.Templates)
+			assert.Equal(t, st.Templates, goerr.NewStackTrace(goerr.Wrap(parsed.Error)).Templates)
+		}
+	}
+}